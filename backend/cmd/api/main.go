@@ -1,17 +1,21 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"backend/internal/audit"
 	"backend/internal/config"
 	"backend/internal/database"
 	"backend/internal/geocoding"
 	httpmw "backend/internal/http"
+	"backend/internal/lifecycle"
 	"backend/internal/organizations"
 	"backend/internal/taxonomies"
+	"backend/internal/tiles"
 )
 
 func main() {
@@ -31,10 +35,21 @@ func main() {
 	auditRepo := audit.NewRepository(db)
 	taxRepo := taxonomies.NewRepository(db)
 	orgService := organizations.NewService(orgRepo, auditRepo, taxRepo)
+	if n, err := orgService.BackfillSlugs(context.Background()); err != nil {
+		log.Printf("slugs: backfill failed: %v", err)
+	} else if n > 0 {
+		log.Printf("slugs: assigned %d", n)
+	}
 	geocoder := geocoding.NewNominatimClient("LODO-Geocode-MVP")
-	orgHandler := organizations.NewHandler(orgService, orgRepo, geocoder)
+	tileCache := tiles.NewCache(60*time.Second, 5000)
+	orgService.OnPublicChange(tileCache.Invalidate)
+	// Publicación programada (publishAt / unpublishAt); seguro con varias instancias
+	go orgService.RunScheduler(context.Background(), cfg.SchedulerInterval)
+	orgHandler := organizations.NewHandler(orgService, orgRepo, geocoder, tileCache)
 
 	taxHandler := taxonomies.NewHandler(taxRepo)
+	auditHandler := audit.NewHandler(auditRepo)
+	auditHandler.UseAliases(orgRepo.FindAliases)
 
 	// 4. Router HTTP
 	mux := http.NewServeMux()
@@ -51,6 +66,9 @@ func main() {
 	// Endpoint público (mapa)
 	publicMux.HandleFunc("/public/organizations", orgHandler.ListPublic)
 	publicMux.HandleFunc("/public/organizations/aggregates", orgHandler.Aggregates)
+	publicMux.HandleFunc("/public/organizations/export", orgHandler.ExportPublic)
+	publicMux.HandleFunc("/public/organizations/clusters", orgHandler.Clusters)
+	publicMux.HandleFunc("/public/organizations/search", orgHandler.SearchArea)
 	publicMux.HandleFunc("/public/organizations/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/public/organizations/" {
 			http.Error(w, "Not found", http.StatusNotFound)
@@ -60,6 +78,10 @@ func main() {
 	})
 
 	publicMux.HandleFunc("/public/taxonomies", taxHandler.ListPublic)
+	publicMux.HandleFunc("/public/stats/timeseries", orgHandler.Timeseries)
+
+	// Vector tiles del mapa: /public/tiles/{z}/{x}/{y}.mvt
+	publicMux.HandleFunc("/public/tiles/", orgHandler.Tile)
 
 	// --- RUTAS DE ADMIN ---
 	adminMux := http.NewServeMux()
@@ -81,16 +103,36 @@ func main() {
 	adminMux.HandleFunc("/organizations/", func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
 		switch {
+		case path == "/organizations/import":
+			orgHandler.Import(w, r)
+		case path == "/organizations/export":
+			orgHandler.Export(w, r)
+		case path == "/organizations/duplicates":
+			orgHandler.Duplicates(w, r)
+		case strings.Contains(path, "/revisions"):
+			// Igual que /versions: /revisions/{rid}/review no es el /review del ciclo de vida
+			orgHandler.Revisions(w, r)
+		case strings.Contains(path, "/versions"):
+			// Antes que los sufijos: /versions/{n}/restore no es el /restore del ciclo de vida
+			orgHandler.Versions(w, r)
 		case strings.HasSuffix(path, "/review"):
 			orgHandler.SubmitForReview(w, r)
 		case strings.HasSuffix(path, "/publish"):
 			orgHandler.Publish(w, r)
 		case strings.HasSuffix(path, "/archive"):
 			orgHandler.Archive(w, r)
+		case strings.HasSuffix(path, "/reject"):
+			orgHandler.Reject(w, r)
+		case strings.HasSuffix(path, "/restore"):
+			orgHandler.Restore(w, r)
 		case strings.HasSuffix(path, "/geocode"):
 			orgHandler.Geocode(w, r)
 		case strings.HasSuffix(path, "/coordinates"):
 			orgHandler.PatchCoordinates(w, r)
+		case strings.HasSuffix(path, "/merge"):
+			orgHandler.Merge(w, r)
+		case strings.HasSuffix(path, "/history"):
+			auditHandler.History(w, r)
 		default:
 			// Si no tiene sufijo conocido, intentamos CRUD base
 			switch r.Method {
@@ -98,6 +140,8 @@ func main() {
 				orgHandler.GetByID(w, r)
 			case http.MethodPut:
 				orgHandler.Update(w, r)
+			case http.MethodPatch:
+				orgHandler.Patch(w, r)
 			case http.MethodDelete:
 				orgHandler.Delete(w, r)
 			default:
@@ -106,10 +150,18 @@ func main() {
 		}
 	})
 
+	// Máquina de estados (para que la UI muestre solo las acciones válidas)
+	adminMux.HandleFunc("/lifecycle", lifecycle.GraphHandler)
+
+	// Log de auditoría (filtros: entityType, entityId, action, actor, from, to)
+	adminMux.HandleFunc("/audit", auditHandler.List)
+
 	// Unir todo en el mux principal
 	mux.Handle("/public/", publicMux)
 	mux.Handle("/organizations", httpmw.Auth(cfg, adminMux))
 	mux.Handle("/organizations/", httpmw.Auth(cfg, adminMux))
+	mux.Handle("/audit", httpmw.Auth(cfg, adminMux))
+	mux.Handle("/lifecycle", httpmw.Auth(cfg, adminMux))
 	mux.Handle("/health", publicMux)
 
 	// 5. Levantar servidor con CORS
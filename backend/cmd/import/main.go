@@ -0,0 +1,71 @@
+// Comando import: carga organizaciones desde una planilla CSV o XLSX.
+//
+// Uso:
+//
+//	go run ./cmd/import -file partners.xlsx            # dry-run, solo reporte
+//	go run ./cmd/import -file partners.xlsx -commit    # crea las filas válidas como DRAFT
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"backend/internal/audit"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/organizations"
+	"backend/internal/spreadsheet"
+	"backend/internal/taxonomies"
+)
+
+func main() {
+	file := flag.String("file", "", "ruta al archivo CSV o XLSX")
+	commit := flag.Bool("commit", false, "crear las filas válidas (por defecto solo valida)")
+	actor := flag.String("actor", "cli/import", "usuario registrado en auditoría")
+	allowDuplicates := flag.Bool("allow-duplicates", false, "no marcar como inválidas las filas que parecen duplicadas")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rows, err := spreadsheet.Read(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cfg := config.Load()
+	db, err := database.Connect(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	orgService := organizations.NewService(
+		organizations.NewRepository(db),
+		audit.NewRepository(db),
+		taxonomies.NewRepository(db),
+	)
+
+	report, err := orgService.Import(rows, !*commit, *allowDuplicates, *actor)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+
+	log.Printf("rows: %d, valid: %d, invalid: %d, created: %d",
+		report.TotalRows, report.ValidRows, report.InvalidRows, report.Created)
+	if report.InvalidRows > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,157 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+type Handler struct {
+	repo    *Repository
+	aliases func(id string) ([]string, error)
+}
+
+func NewHandler(repo *Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+// UseAliases registra cómo obtener los ids absorbidos por una organización
+// (merge), para que su historial incluya los eventos previos a la fusión.
+func (h *Handler) UseAliases(fn func(id string) ([]string, error)) {
+	h.aliases = fn
+}
+
+// List devuelve el log de auditoría filtrado y paginado (más reciente primero).
+// Filtros: entityType, entityId, action, actor, from, to, limit, offset.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	qp := r.URL.Query()
+	f := Filter{
+		EntityType:  qp.Get("entityType"),
+		EntityID:    qp.Get("entityId"),
+		Action:      qp.Get("action"),
+		PerformedBy: qp.Get("actor"),
+	}
+	if err := parsePageParams(r, &f); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.writePage(w, f)
+}
+
+// History devuelve la línea de tiempo de una organización en orden cronológico.
+// Incluye los eventos de las organizaciones que se fusionaron en ella; cada
+// evento conserva su entityId original.
+// Ruta: /organizations/{id}/history
+func (h *Handler) History(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 || parts[1] == "" {
+		http.Error(w, "invalid URL", http.StatusBadRequest)
+		return
+	}
+
+	f := Filter{
+		EntityType: "Organization",
+		EntityID:   parts[1],
+		Action:     r.URL.Query().Get("action"),
+		Ascending:  true,
+	}
+	if err := parsePageParams(r, &f); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if h.aliases != nil {
+		aliases, err := h.aliases(f.EntityID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		f.AliasIDs = aliases
+	}
+
+	h.writePage(w, f)
+}
+
+func (h *Handler) writePage(w http.ResponseWriter, f Filter) {
+	page, err := h.repo.Find(f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// parsePageParams lee from/to/limit/offset del query string.
+// from/to aceptan RFC3339 o YYYY-MM-DD; una fecha sola en "to" incluye el día completo.
+func parsePageParams(r *http.Request, f *Filter) error {
+	qp := r.URL.Query()
+
+	if v := qp.Get("from"); v != "" {
+		t, _, err := parseTime(v)
+		if err != nil {
+			return err
+		}
+		f.From = &t
+	}
+	if v := qp.Get("to"); v != "" {
+		t, dateOnly, err := parseTime(v)
+		if err != nil {
+			return err
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		f.To = &t
+	}
+
+	f.Limit = defaultPageSize
+	if v := qp.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid limit")
+		}
+		if n > maxPageSize {
+			n = maxPageSize
+		}
+		f.Limit = n
+	}
+	if v := qp.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid offset")
+		}
+		f.Offset = n
+	}
+	return nil
+}
+
+func parseTime(v string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date %q (use RFC3339 or YYYY-MM-DD)", v)
+	}
+	return t, true, nil
+}
@@ -3,13 +3,42 @@ package audit
 import "time"
 
 // AuditLog representa un evento de auditoría del sistema.
+// Los cambios de estado usan FromStatus/ToStatus; los cambios de datos
+// usan FieldName/OldValue/NewValue (un evento por campo modificado).
 type AuditLog struct {
-	ID             int64
-	EntityType     string
-	EntityID       string
-	Action         string
-	FromStatus     string
-	ToStatus       string
-	PerformedBy    string
-	PerformedAt    time.Time
+	ID          int64     `json:"id"`
+	EntityType  string    `json:"entityType"`
+	EntityID    string    `json:"entityId"`
+	Action      string    `json:"action"`
+	FromStatus  string    `json:"fromStatus,omitempty"`
+	ToStatus    string    `json:"toStatus,omitempty"`
+	FieldName   string    `json:"fieldName,omitempty"`
+	OldValue    *string   `json:"oldValue,omitempty"`
+	NewValue    *string   `json:"newValue,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	PerformedBy string    `json:"performedBy"`
+	PerformedAt time.Time `json:"performedAt"`
+}
+
+// Filter define los criterios para consultar el log de auditoría.
+// Los campos vacíos no filtran. To es exclusivo.
+type Filter struct {
+	EntityType  string
+	EntityID    string
+	AliasIDs    []string // otros ids que cuentan como EntityID (organizaciones fusionadas)
+	Action      string
+	PerformedBy string
+	From        *time.Time
+	To          *time.Time
+	Ascending   bool
+	Limit       int
+	Offset      int
+}
+
+// Page es una página de resultados del log junto al total de coincidencias.
+type Page struct {
+	Items  []AuditLog `json:"items"`
+	Total  int        `json:"total"`
+	Limit  int        `json:"limit"`
+	Offset int        `json:"offset"`
 }
@@ -3,6 +3,7 @@ package audit
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 )
 
 type Repository struct {
@@ -13,22 +14,143 @@ func NewRepository(db *sql.DB) *Repository {
 	return &Repository{DB: db}
 }
 
+const insertAuditSQL = `
+	INSERT INTO audit_logs
+	(entity_type, entity_id, action, from_status, to_status,
+	 field_name, old_value, new_value, reason, performed_by)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
 func (r *Repository) Log(event interface{}) error {
 	auditEvent, ok := event.(*AuditLog)
 	if !ok {
 		return fmt.Errorf("unexpected audit event type: %T", event)
 	}
 
-	_, err := r.DB.Exec(`
-		INSERT INTO audit_logs
-		(entity_type, entity_id, action, from_status, to_status, performed_by)
-		VALUES (?, ?, ?, ?, ?, ?)`,
-		auditEvent.EntityType,
-		auditEvent.EntityID,
-		auditEvent.Action,
-		auditEvent.FromStatus,
-		auditEvent.ToStatus,
-		auditEvent.PerformedBy,
-	)
+	_, err := r.DB.Exec(insertAuditSQL, insertArgs(auditEvent)...)
 	return err
 }
+
+// LogAll registra varios eventos en una sola transacción (p.ej. todos los
+// campos modificados por un mismo Update).
+func (r *Repository) LogAll(events []*AuditLog) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(insertAuditSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, e := range events {
+		if _, err := stmt.Exec(insertArgs(e)...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func insertArgs(e *AuditLog) []any {
+	return []any{
+		e.EntityType,
+		e.EntityID,
+		e.Action,
+		nullIfEmpty(e.FromStatus),
+		nullIfEmpty(e.ToStatus),
+		nullIfEmpty(e.FieldName),
+		e.OldValue,
+		e.NewValue,
+		nullIfEmpty(e.Reason),
+		e.PerformedBy,
+	}
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// Find devuelve una página de eventos que cumplen el filtro y el total de coincidencias.
+func (r *Repository) Find(f Filter) (*Page, error) {
+	where := " WHERE 1=1"
+	args := make([]any, 0)
+
+	if f.EntityType != "" {
+		where += " AND entity_type = ?"
+		args = append(args, f.EntityType)
+	}
+	if f.EntityID != "" {
+		ids := append([]string{f.EntityID}, f.AliasIDs...)
+		where += " AND entity_id IN (?" + strings.Repeat(", ?", len(ids)-1) + ")"
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	if f.Action != "" {
+		where += " AND action = ?"
+		args = append(args, f.Action)
+	}
+	if f.PerformedBy != "" {
+		where += " AND performed_by = ?"
+		args = append(args, f.PerformedBy)
+	}
+	if f.From != nil {
+		where += " AND performed_at >= ?"
+		args = append(args, *f.From)
+	}
+	if f.To != nil {
+		where += " AND performed_at < ?"
+		args = append(args, *f.To)
+	}
+
+	page := &Page{Items: make([]AuditLog, 0), Limit: f.Limit, Offset: f.Offset}
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM audit_logs`+where, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	order := " ORDER BY performed_at DESC, id DESC"
+	if f.Ascending {
+		order = " ORDER BY performed_at ASC, id ASC"
+	}
+	query := `
+		SELECT id, entity_type, entity_id, action, from_status, to_status,
+		       field_name, old_value, new_value, reason, performed_by, performed_at
+		FROM audit_logs` + where + order + " LIMIT ? OFFSET ?"
+	args = append(args, f.Limit, f.Offset)
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e AuditLog
+		var entityType, entityID, action, fromStatus, toStatus, fieldName, reason, performedBy sql.NullString
+		if err := rows.Scan(
+			&e.ID, &entityType, &entityID, &action, &fromStatus, &toStatus,
+			&fieldName, &e.OldValue, &e.NewValue, &reason, &performedBy, &e.PerformedAt,
+		); err != nil {
+			return nil, err
+		}
+		e.EntityType = entityType.String
+		e.EntityID = entityID.String
+		e.Action = action.String
+		e.FromStatus = fromStatus.String
+		e.ToStatus = toStatus.String
+		e.FieldName = fieldName.String
+		e.Reason = reason.String
+		e.PerformedBy = performedBy.String
+		page.Items = append(page.Items, e)
+	}
+	return page, rows.Err()
+}
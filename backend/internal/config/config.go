@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,6 +15,9 @@ type Config struct {
 	DBPass     string
 	DBName     string
 	AdminToken string
+
+	// SchedulerInterval es cada cuánto corre la publicación programada (SCHEDULER_INTERVAL, p.ej. "30s").
+	SchedulerInterval time.Duration
 }
 
 func Load() Config {
@@ -30,5 +34,21 @@ func Load() Config {
 		DBPass:     os.Getenv("DB_PASS"),
 		DBName:     os.Getenv("DB_NAME"),
 		AdminToken: os.Getenv("ADMIN_TOKEN"),
+
+		SchedulerInterval: durationEnv("SCHEDULER_INTERVAL", time.Minute),
+	}
+}
+
+// durationEnv lee una duración de Go ("90s", "5m"); si falta o es inválida usa def.
+func durationEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("%s=%q is not a valid duration, using %s", key, v, def)
+		return def
 	}
+	return d
 }
@@ -11,7 +11,8 @@ func CORS(next http.Handler) http.Handler {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 			w.Header().Set("Vary", "Origin")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Actor, If-Match, If-None-Match")
+			w.Header().Set("Access-Control-Expose-Headers", "ETag, Link, X-Total-Count, X-Next-Cursor")
 		}
 
 		// Preflight
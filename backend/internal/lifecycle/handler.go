@@ -0,0 +1,29 @@
+package lifecycle
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Graph es la representación pública de la máquina de estados.
+type Graph struct {
+	States      []string            `json:"states"`
+	Transitions []Transition        `json:"transitions"`
+	Allowed     map[string][]string `json:"allowed"`
+}
+
+// GraphHandler expone la máquina de estados para que la UI muestre solo
+// las acciones permitidas según el estado actual.
+func GraphHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Graph{
+		States:      States,
+		Transitions: Transitions,
+		Allowed:     AllowedTransitions,
+	})
+}
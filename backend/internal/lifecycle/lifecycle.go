@@ -1,14 +1,52 @@
 package lifecycle
 
-import "errors"
+import "fmt"
+
+// Estados del ciclo de vida de una organización.
+const (
+	Draft     = "DRAFT"
+	InReview  = "IN_REVIEW"
+	Published = "PUBLISHED"
+	Archived  = "ARCHIVED"
+)
+
+// States lista los estados en el orden natural del ciclo.
+var States = []string{Draft, InReview, Published, Archived}
+
+// Transition describe un cambio de estado permitido.
+// Action es el nombre que se registra en auditoría y Endpoint el sufijo
+// HTTP que la dispara (/organizations/{id}/{endpoint}).
+type Transition struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Action   string `json:"action"`
+	Endpoint string `json:"endpoint"`
+}
+
+// Transitions es la única definición de la máquina de estados.
+// Corresponde al Word (Fase 1.4) más RESTORE, que devuelve un archivado a
+// DRAFT para corregirlo.
+var Transitions = []Transition{
+	{From: Draft, To: InReview, Action: "SUBMIT_FOR_REVIEW", Endpoint: "review"},
+	{From: InReview, To: Published, Action: "PUBLISH", Endpoint: "publish"},
+	{From: InReview, To: Draft, Action: "REJECT", Endpoint: "reject"},
+	{From: Published, To: Archived, Action: "ARCHIVE", Endpoint: "archive"},
+	{From: Archived, To: Draft, Action: "RESTORE", Endpoint: "restore"},
+}
 
 // AllowedTransitions define qué cambios de estado están permitidos.
-// Corresponde exactamente al Word (Fase 1.4).
-var AllowedTransitions = map[string][]string{
-	"DRAFT":     {"IN_REVIEW"},
-	"IN_REVIEW": {"PUBLISHED", "DRAFT"},
-	"PUBLISHED": {"ARCHIVED"},
-	"ARCHIVED":  {},
+// Se deriva de Transitions.
+var AllowedTransitions = buildAllowed(Transitions)
+
+func buildAllowed(transitions []Transition) map[string][]string {
+	allowed := make(map[string][]string, len(States))
+	for _, s := range States {
+		allowed[s] = []string{}
+	}
+	for _, t := range transitions {
+		allowed[t.From] = append(allowed[t.From], t.To)
+	}
+	return allowed
 }
 
 // CanTransition valida si un estado puede pasar a otro.
@@ -29,7 +67,17 @@ func CanTransition(from string, to string) bool {
 // ValidateTransition devuelve error si la transición no es válida.
 func ValidateTransition(from string, to string) error {
 	if !CanTransition(from, to) {
-		return errors.New("invalid lifecycle transition")
+		return fmt.Errorf("invalid lifecycle transition from %s to %s", from, to)
 	}
 	return nil
 }
+
+// Find devuelve la transición from -> to, si existe.
+func Find(from string, to string) (Transition, bool) {
+	for _, t := range Transitions {
+		if t.From == from && t.To == to {
+			return t, true
+		}
+	}
+	return Transition{}, false
+}
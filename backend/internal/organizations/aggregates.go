@@ -1,17 +1,96 @@
 package organizations
 
+import (
+	"fmt"
+	"strconv"
+)
+
 // AggregateItem representa un valor y su frecuencia.
 type AggregateItem struct {
 	Value string `json:"value"`
 	Count int    `json:"count"`
 }
 
+// YearBucket es un tramo del histograma de año de fundación: [From, To].
+type YearBucket struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Count int `json:"count"`
+}
+
 // AggregatesResponse agrupa los conteos para todas las dimensiones del mapa.
 type AggregatesResponse struct {
 	Countries         []AggregateItem `json:"countries"`
+	Regions           []AggregateItem `json:"regions"`
+	Cities            []AggregateItem `json:"cities"`
 	SectorsPrimary    []AggregateItem `json:"sectorsPrimary"`
 	SectorsSecondary  []AggregateItem `json:"sectorsSecondary"`
 	OrganizationTypes []AggregateItem `json:"organizationTypes"`
 	Stages            []AggregateItem `json:"stages"`
 	OutcomeStatuses   []AggregateItem `json:"outcomeStatuses"`
+
+	// Campos multivaluados: cada organización cuenta una vez por valor
+	Technologies []AggregateItem `json:"technologies"`
+	ImpactAreas  []AggregateItem `json:"impactAreas"`
+	Badges       []AggregateItem `json:"badges"`
+	Tags         []AggregateItem `json:"tags"`
+
+	YearFounded []YearBucket `json:"yearFounded"`
+}
+
+// facetSpec describe un facet del panel de filtros. Se calcula sin su
+// propio filtro (faceting disyuntivo): elegir un país no colapsa el facet
+// de países a ese único valor.
+type facetSpec struct {
+	Param       string
+	Column      string
+	Multi       bool // columna con arreglo JSON
+	IgnoreEmpty bool
+	Field       func(*AggregatesResponse) *[]AggregateItem
+}
+
+var facets = []facetSpec{
+	{Param: "country", Column: "country", Field: func(a *AggregatesResponse) *[]AggregateItem { return &a.Countries }},
+	{Param: "region", Column: "region", IgnoreEmpty: true, Field: func(a *AggregatesResponse) *[]AggregateItem { return &a.Regions }},
+	{Param: "city", Column: "city", IgnoreEmpty: true, Field: func(a *AggregatesResponse) *[]AggregateItem { return &a.Cities }},
+	{Param: "sectorPrimary", Column: "sector_primary", Field: func(a *AggregatesResponse) *[]AggregateItem { return &a.SectorsPrimary }},
+	{Param: "sectorSecondary", Column: "sector_secondary", IgnoreEmpty: true, Field: func(a *AggregatesResponse) *[]AggregateItem { return &a.SectorsSecondary }},
+	{Param: "organizationType", Column: "organization_type", Field: func(a *AggregatesResponse) *[]AggregateItem { return &a.OrganizationTypes }},
+	{Param: "stage", Column: "stage", IgnoreEmpty: true, Field: func(a *AggregatesResponse) *[]AggregateItem { return &a.Stages }},
+	{Param: "outcomeStatus", Column: "outcome_status", Field: func(a *AggregatesResponse) *[]AggregateItem { return &a.OutcomeStatuses }},
+	{Param: "technology", Column: "technology_json", Multi: true, Field: func(a *AggregatesResponse) *[]AggregateItem { return &a.Technologies }},
+	{Param: "impactArea", Column: "impact_area_json", Multi: true, Field: func(a *AggregatesResponse) *[]AggregateItem { return &a.ImpactAreas }},
+	{Param: "badge", Column: "badge_json", Multi: true, Field: func(a *AggregatesResponse) *[]AggregateItem { return &a.Badges }},
+	{Param: "tag", Column: "tags_json", Multi: true, Field: func(a *AggregatesResponse) *[]AggregateItem { return &a.Tags }},
+}
+
+// Tamaño de tramo del histograma de yearFounded (?yearBucket=).
+const (
+	defaultYearBucket = 5
+	maxYearBucket     = 100
+)
+
+// withoutFilter copia params sin el filtro indicado (ni su forma negada ni su modo any/all).
+func withoutFilter(params map[string]string, param string) map[string]string {
+	out := make(map[string]string, len(params))
+	for k, v := range params {
+		if k == param || k == negatedPrefix+param || k == param+"Match" {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// parseYearBucket lee ?yearBucket= (años por tramo del histograma).
+func parseYearBucket(params map[string]string) (int, error) {
+	s := params["yearBucket"]
+	if s == "" {
+		return defaultYearBucket, nil
+	}
+	size, err := strconv.Atoi(s)
+	if err != nil || size < 1 || size > maxYearBucket {
+		return 0, fmt.Errorf("yearBucket must be between 1 and %d", maxYearBucket)
+	}
+	return size, nil
 }
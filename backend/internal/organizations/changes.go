@@ -0,0 +1,112 @@
+package organizations
+
+import (
+	"strconv"
+	"time"
+)
+
+// fieldSpec describe un campo editable: su nombre en la API, su columna en la
+// DB y cómo obtener el valor tal como se persiste.
+type fieldSpec struct {
+	Name   string
+	Column string
+	Value  func(o *Organization) any
+}
+
+// editableFields lista los campos que un Update puede modificar.
+// id, status y timestamps quedan fuera a propósito.
+var editableFields = []fieldSpec{
+	{"name", "name", func(o *Organization) any { return o.Name }},
+	{"organizationType", "organization_type", func(o *Organization) any { return o.OrganizationType }},
+	{"sectorPrimary", "sector_primary", func(o *Organization) any { return o.SectorPrimary }},
+	{"sectorSecondary", "sector_secondary", func(o *Organization) any { return o.SectorSecondary }},
+	{"stage", "stage", func(o *Organization) any { return o.Stage }},
+	{"outcomeStatus", "outcome_status", func(o *Organization) any { return o.OutcomeStatus }},
+	{"country", "country", func(o *Organization) any { return o.Country }},
+	{"region", "region", func(o *Organization) any { return o.Region }},
+	{"city", "city", func(o *Organization) any { return o.City }},
+	{"lat", "lat", func(o *Organization) any { return o.Lat }},
+	{"lng", "lng", func(o *Organization) any { return o.Lng }},
+	{"website", "website", func(o *Organization) any { return o.Website }},
+	{"notes", "notes", func(o *Organization) any { return o.Notes }},
+	{"description", "description", func(o *Organization) any { return o.Description }},
+	{"yearFounded", "year_founded", func(o *Organization) any { return o.YearFounded }},
+	{"logoUrl", "logo_url", func(o *Organization) any { return o.LogoURL }},
+	{"linkedinUrl", "linkedin_url", func(o *Organization) any { return o.LinkedInURL }},
+	{"contactEmail", "contact_email", func(o *Organization) any { return o.ContactEmail }},
+	{"contactPhone", "contact_phone", func(o *Organization) any { return o.ContactPhone }},
+	{"instagramUrl", "instagram_url", func(o *Organization) any { return o.InstagramURL }},
+	{"tags", "tags_json", func(o *Organization) any { return toJSON(o.Tags) }},
+	{"technology", "technology_json", func(o *Organization) any { return toJSON(o.Technology) }},
+	{"impactArea", "impact_area_json", func(o *Organization) any { return toJSON(o.ImpactArea) }},
+	{"badge", "badge_json", func(o *Organization) any { return toJSON(o.Badge) }},
+	{"publishAt", "publish_at", func(o *Organization) any { return o.PublishAt }},
+	{"unpublishAt", "unpublish_at", func(o *Organization) any { return o.UnpublishAt }},
+}
+
+// FieldChange es la diferencia de un campo entre dos versiones de una organización.
+// OldValue/NewValue son nil cuando el valor es NULL.
+type FieldChange struct {
+	Field    string  `json:"field"`
+	Column   string  `json:"-"`
+	OldValue *string `json:"oldValue"`
+	NewValue *string `json:"newValue"`
+}
+
+// diffOrganizations devuelve los campos editables que difieren entre before y after.
+func diffOrganizations(before, after *Organization) []FieldChange {
+	changes := make([]FieldChange, 0)
+	for _, f := range editableFields {
+		oldV := valueString(f.Value(before))
+		newV := valueString(f.Value(after))
+		if equalOptional(oldV, newV) {
+			continue
+		}
+		changes = append(changes, FieldChange{
+			Field:    f.Name,
+			Column:   f.Column,
+			OldValue: oldV,
+			NewValue: newV,
+		})
+	}
+	return changes
+}
+
+// valueString representa un valor persistible como texto para comparar y auditar.
+func valueString(v any) *string {
+	var s string
+	switch t := v.(type) {
+	case string:
+		s = t
+	case *string:
+		if t == nil {
+			return nil
+		}
+		s = *t
+	case *int:
+		if t == nil {
+			return nil
+		}
+		s = strconv.Itoa(*t)
+	case *float64:
+		if t == nil {
+			return nil
+		}
+		s = strconv.FormatFloat(*t, 'f', -1, 64)
+	case *time.Time:
+		if t == nil {
+			return nil
+		}
+		s = t.UTC().Format(time.RFC3339)
+	default:
+		return nil
+	}
+	return &s
+}
+
+func equalOptional(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,86 @@
+package organizations
+
+import "math"
+
+const (
+	// clusterMaxZoom: desde este zoom se devuelven puntos individuales.
+	clusterMaxZoom = 14
+	// clusterRadiusPx es el tamaño de celda de la grilla, en píxeles de pantalla.
+	clusterRadiusPx = 60
+	tileSizePx      = 256
+)
+
+// MapPoint es la proyección mínima de una organización para el mapa.
+type MapPoint struct {
+	ID               string  `json:"id"`
+	Name             string  `json:"name"`
+	OrganizationType string  `json:"organizationType"`
+	SectorPrimary    string  `json:"sectorPrimary"`
+	Lat              float64 `json:"lat"`
+	Lng              float64 `json:"lng"`
+}
+
+// Cluster agrupa los puntos de una celda de la grilla.
+// BBox es [minLat, minLng, maxLat, maxLng], el mismo orden que el filtro bbox.
+type Cluster struct {
+	Lat   float64    `json:"lat"`
+	Lng   float64    `json:"lng"`
+	Count int        `json:"count"`
+	BBox  [4]float64 `json:"bbox"`
+}
+
+// ClustersResponse devuelve clusters o, a zoom alto, puntos individuales.
+type ClustersResponse struct {
+	Zoom     int        `json:"zoom"`
+	Total    int        `json:"total"`
+	Clusters []Cluster  `json:"clusters"`
+	Points   []MapPoint `json:"points"`
+}
+
+// project convierte lat/lng a píxeles Web Mercator en el zoom dado.
+func project(lat, lng float64, zoom int) (float64, float64) {
+	scale := tileSizePx * math.Exp2(float64(zoom))
+	lat = math.Max(math.Min(lat, 85.05112878), -85.05112878)
+	sin := math.Sin(lat * math.Pi / 180)
+	x := (lng + 180) / 360 * scale
+	y := (0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)) * scale
+	// En el límite el redondeo puede dejar y apenas fuera del mundo
+	return x, math.Max(0, math.Min(y, scale))
+}
+
+// clusterPoints agrupa los puntos en una grilla de clusterRadiusPx píxeles.
+// El centro de cada cluster es el promedio de sus puntos.
+func clusterPoints(points []MapPoint, zoom int) []Cluster {
+	type cell struct{ x, y int64 }
+	index := make(map[cell]int)
+	clusters := make([]Cluster, 0)
+	sums := make([][2]float64, 0)
+
+	for _, p := range points {
+		px, py := project(p.Lat, p.Lng, zoom)
+		c := cell{int64(px / clusterRadiusPx), int64(py / clusterRadiusPx)}
+
+		i, ok := index[c]
+		if !ok {
+			i = len(clusters)
+			index[c] = i
+			clusters = append(clusters, Cluster{BBox: [4]float64{p.Lat, p.Lng, p.Lat, p.Lng}})
+			sums = append(sums, [2]float64{})
+		}
+
+		cl := &clusters[i]
+		cl.Count++
+		sums[i][0] += p.Lat
+		sums[i][1] += p.Lng
+		cl.BBox[0] = math.Min(cl.BBox[0], p.Lat)
+		cl.BBox[1] = math.Min(cl.BBox[1], p.Lng)
+		cl.BBox[2] = math.Max(cl.BBox[2], p.Lat)
+		cl.BBox[3] = math.Max(cl.BBox[3], p.Lng)
+	}
+
+	for i := range clusters {
+		clusters[i].Lat = sums[i][0] / float64(clusters[i].Count)
+		clusters[i].Lng = sums[i][1] / float64(clusters[i].Count)
+	}
+	return clusters
+}
@@ -0,0 +1,87 @@
+package organizations
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	x, y := project(0, 0, 0)
+	if x != 128 || math.Abs(y-128) > 1e-9 {
+		t.Errorf("project(0, 0, 0) = %v, %v; want 128, 128", x, y)
+	}
+	// Fuera del límite de Web Mercator se recorta en vez de irse a infinito
+	if _, y := project(90, 0, 2); math.IsInf(y, 0) || y < 0 {
+		t.Errorf("project(90, 0, 2) y = %v", y)
+	}
+	if x, _ := project(0, 180, 3); x != 2048 {
+		t.Errorf("project(0, 180, 3) x = %v, want 2048", x)
+	}
+}
+
+func TestClusterPoints(t *testing.T) {
+	points := []MapPoint{
+		{ID: "ba1", Lat: -34.60, Lng: -58.38},
+		{ID: "ba2", Lat: -34.61, Lng: -58.39},
+		{ID: "mvd", Lat: -34.90, Lng: -56.16},
+		{ID: "mad", Lat: 40.42, Lng: -3.70},
+	}
+	tests := []struct {
+		zoom   int
+		counts []int
+	}{
+		{0, []int{1, 3}},        // el Río de la Plata en una celda, Madrid en otra
+		{6, []int{1, 1, 2}},     // Montevideo se separa
+		{14, []int{1, 1, 1, 1}}, // a zoom alto cada punto es su propio cluster
+	}
+	for _, tt := range tests {
+		clusters := clusterPoints(points, tt.zoom)
+		counts := make([]int, 0, len(clusters))
+		total := 0
+		for _, c := range clusters {
+			counts = append(counts, c.Count)
+			total += c.Count
+		}
+		sort.Ints(counts)
+		if !equalInts(counts, tt.counts) {
+			t.Errorf("zoom %d: counts = %v, want %v", tt.zoom, counts, tt.counts)
+		}
+		if total != len(points) {
+			t.Errorf("zoom %d: clustered %d points, want %d", tt.zoom, total, len(points))
+		}
+	}
+}
+
+func TestClusterPointsCenterAndBBox(t *testing.T) {
+	points := []MapPoint{
+		{Lat: -34.60, Lng: -58.38},
+		{Lat: -34.62, Lng: -58.40},
+	}
+	clusters := clusterPoints(points, 6)
+	if len(clusters) != 1 {
+		t.Fatalf("got %d clusters, want 1", len(clusters))
+	}
+	c := clusters[0]
+	if math.Abs(c.Lat+34.61) > 1e-9 || math.Abs(c.Lng+58.39) > 1e-9 {
+		t.Errorf("center = %v, %v; want the average -34.61, -58.39", c.Lat, c.Lng)
+	}
+	if want := [4]float64{-34.62, -58.40, -34.60, -58.38}; c.BBox != want {
+		t.Errorf("bbox = %v, want %v", c.BBox, want)
+	}
+	if got := clusterPoints(nil, 3); len(got) != 0 {
+		t.Errorf("clusterPoints(nil) = %v, want none", got)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package organizations
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrPreconditionFailed indica que la organización cambió desde que el cliente la leyó.
+var ErrPreconditionFailed = errors.New("precondition failed: organization was modified by someone else")
+
+// Change describe quién realiza una modificación y bajo qué condiciones.
+type Change struct {
+	Actor   string
+	IfMatch int    // versión esperada (If-Match); 0 = sin precondición
+	Reason  string // motivo opcional, se guarda en auditoría
+}
+
+// checkVersion valida la precondición If-Match contra la versión actual.
+func (c Change) checkVersion(org *Organization) error {
+	if c.IfMatch != 0 && c.IfMatch != org.Version {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// etag devuelve el ETag (fuerte) de una organización, derivado de su versión.
+func etag(org *Organization) string {
+	return fmt.Sprintf(`"%d"`, org.Version)
+}
+
+// changeFromRequest arma un Change con el actor y el If-Match del request.
+// "*" equivale a no exigir versión; un valor que no es un ETag nuestro nunca coincide.
+func changeFromRequest(r *http.Request) (Change, error) {
+	ch := Change{Actor: actorFromRequest(r)}
+
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return ch, nil
+	}
+
+	v, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`))
+	if err != nil || v < 1 {
+		return ch, ErrPreconditionFailed
+	}
+	ch.IfMatch = v
+	return ch, nil
+}
+
+// writeChangeError responde 412 si err es un conflicto de versión, o 409 si
+// la edición choca con una revisión en curso.
+// Devuelve true si ya respondió.
+func writeChangeError(w http.ResponseWriter, err error) bool {
+	if errors.Is(err, ErrPreconditionFailed) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return true
+	}
+	if errors.Is(err, ErrRevisionInReview) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,405 @@
+package organizations
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// nameSimilarityThreshold es la similitud mínima (0..1) entre nombres
+// normalizados de un mismo país para considerarlos posible duplicado.
+const nameSimilarityThreshold = 0.85
+
+// Motivos de coincidencia que se informan en cada candidato.
+const (
+	reasonWebsite  = "same website domain"
+	reasonLinkedIn = "same LinkedIn page"
+	reasonName     = "similar name in same country"
+)
+
+// DuplicateCandidate es un registro existente que parece la misma organización.
+type DuplicateCandidate struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Country string   `json:"country"`
+	Website *string  `json:"website,omitempty"`
+	Status  string   `json:"status"`
+	Score   float64  `json:"score,omitempty"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// DuplicateError indica que la organización parece ya cargada.
+// Se resuelve reintentando con ?allowDuplicate=true.
+type DuplicateError struct {
+	Candidates []DuplicateCandidate
+}
+
+func (e *DuplicateError) Error() string {
+	ids := make([]string, len(e.Candidates))
+	for i, c := range e.Candidates {
+		ids[i] = c.ID
+	}
+	return fmt.Sprintf("possible duplicate of: %s", strings.Join(ids, ", "))
+}
+
+// writeDuplicateError responde 409 con los candidatos si err es un
+// DuplicateError; devuelve false en otro caso.
+func writeDuplicateError(w http.ResponseWriter, err error) bool {
+	var dup *DuplicateError
+	if !errors.As(err, &dup) {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	encodeJSON(w, map[string]any{
+		"error":      dup.Error(),
+		"candidates": dup.Candidates,
+	})
+	return true
+}
+
+// DuplicateCluster agrupa registros existentes que parecen la misma organización.
+type DuplicateCluster struct {
+	Members []DuplicateCandidate `json:"members"`
+	Reasons []string             `json:"reasons"`
+}
+
+// duplicateRecord son los datos mínimos para comparar organizaciones.
+type duplicateRecord struct {
+	ID          string
+	Name        string
+	Country     string
+	Website     *string
+	LinkedInURL *string
+	Status      string
+}
+
+// duplicateKeys son las claves de comparación ya normalizadas.
+type duplicateKeys struct {
+	domain   string
+	linkedin string
+	name     string
+	country  string
+	tokens   []string
+}
+
+func keysOf(rec *duplicateRecord) duplicateKeys {
+	k := duplicateKeys{
+		name:    normalizeOrgName(rec.Name),
+		country: foldText(strings.TrimSpace(rec.Country)),
+	}
+	if rec.Website != nil {
+		k.domain = registrableDomain(*rec.Website)
+	}
+	if rec.LinkedInURL != nil {
+		k.linkedin = linkedInKey(*rec.LinkedInURL)
+	}
+	k.tokens = strings.Fields(k.name)
+	return k
+}
+
+func recordOf(org *Organization) *duplicateRecord {
+	return &duplicateRecord{
+		ID: org.ID, Name: org.Name, Country: org.Country,
+		Website: org.Website, LinkedInURL: org.LinkedInURL, Status: string(org.Status),
+	}
+}
+
+// matchReasons compara dos registros y devuelve los motivos y un puntaje.
+func matchReasons(a, b duplicateKeys) ([]string, float64) {
+	var reasons []string
+	score := 0.0
+	if a.domain != "" && a.domain == b.domain {
+		reasons = append(reasons, reasonWebsite)
+		score = 1
+	}
+	if a.linkedin != "" && a.linkedin == b.linkedin {
+		reasons = append(reasons, reasonLinkedIn)
+		score = 1
+	}
+	if a.country != "" && a.country == b.country && a.name != "" {
+		if sim := nameSimilarity(a.name, b.name); sim >= nameSimilarityThreshold {
+			reasons = append(reasons, reasonName)
+			score = max(score, sim)
+		}
+	}
+	return reasons, score
+}
+
+// findDuplicates compara org contra records y devuelve los candidatos,
+// del más al menos probable.
+func findDuplicates(org *Organization, records []duplicateRecord) []DuplicateCandidate {
+	keys := keysOf(recordOf(org))
+	candidates := make([]DuplicateCandidate, 0)
+	for i := range records {
+		rec := &records[i]
+		if rec.ID == org.ID {
+			continue
+		}
+		if reasons, score := matchReasons(keys, keysOf(rec)); len(reasons) > 0 {
+			candidates = append(candidates, candidateOf(rec, reasons, score))
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	return candidates
+}
+
+func candidateOf(rec *duplicateRecord, reasons []string, score float64) DuplicateCandidate {
+	return DuplicateCandidate{
+		ID: rec.ID, Name: rec.Name, Country: rec.Country, Website: rec.Website,
+		Status: rec.Status, Score: float64(int(score*100)) / 100, Reasons: reasons,
+	}
+}
+
+// clusterDuplicates agrupa (union-find) los registros que coinciden entre sí.
+// Para no comparar todos contra todos, los nombres solo se comparan si
+// comparten país y al menos una palabra.
+func clusterDuplicates(records []duplicateRecord) []DuplicateCluster {
+	parent := make([]int, len(records))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	reasons := make(map[int]map[string]bool)
+	union := func(i, j int, why []string) {
+		ri, rj := find(i), find(j)
+		if ri != rj {
+			parent[rj] = ri
+			for r := range reasons[rj] {
+				if reasons[ri] == nil {
+					reasons[ri] = make(map[string]bool)
+				}
+				reasons[ri][r] = true
+			}
+		}
+		if reasons[ri] == nil {
+			reasons[ri] = make(map[string]bool)
+		}
+		for _, r := range why {
+			reasons[ri][r] = true
+		}
+	}
+
+	keys := make([]duplicateKeys, len(records))
+	byDomain := make(map[string]int)
+	byLinkedIn := make(map[string]int)
+	byToken := make(map[string][]int)
+	for i := range records {
+		keys[i] = keysOf(&records[i])
+		k := keys[i]
+		if k.domain != "" {
+			if j, ok := byDomain[k.domain]; ok {
+				union(j, i, []string{reasonWebsite})
+			} else {
+				byDomain[k.domain] = i
+			}
+		}
+		if k.linkedin != "" {
+			if j, ok := byLinkedIn[k.linkedin]; ok {
+				union(j, i, []string{reasonLinkedIn})
+			} else {
+				byLinkedIn[k.linkedin] = i
+			}
+		}
+
+		compared := make(map[int]bool)
+		for _, t := range k.tokens {
+			if len(t) < 3 {
+				continue
+			}
+			block := k.country + "|" + t
+			for _, j := range byToken[block] {
+				if compared[j] {
+					continue
+				}
+				compared[j] = true
+				if nameSimilarity(k.name, keys[j].name) >= nameSimilarityThreshold {
+					union(j, i, []string{reasonName})
+				}
+			}
+			byToken[block] = append(byToken[block], i)
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i := range records {
+		root := find(i)
+		groups[root] = append(groups[root], i)
+	}
+	clusters := make([]DuplicateCluster, 0)
+	for root, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		c := DuplicateCluster{Members: make([]DuplicateCandidate, 0, len(members))}
+		for _, i := range members {
+			c.Members = append(c.Members, candidateOf(&records[i], nil, 0))
+		}
+		for r := range reasons[root] {
+			c.Reasons = append(c.Reasons, r)
+		}
+		sort.Strings(c.Reasons)
+		clusters = append(clusters, c)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].Members) != len(clusters[j].Members) {
+			return len(clusters[i].Members) > len(clusters[j].Members)
+		}
+		return clusters[i].Members[0].Name < clusters[j].Members[0].Name
+	})
+	return clusters
+}
+
+// twoLevelSuffixes son segundos niveles habituales bajo un ccTLD
+// ("empresa.com.ar" -> registrable "empresa.com.ar", no "com.ar").
+var twoLevelSuffixes = map[string]bool{
+	"com": true, "org": true, "net": true, "gob": true, "gov": true, "edu": true,
+	"co": true, "ac": true, "mil": true, "nom": true, "int": true, "tur": true,
+}
+
+// sharedHosts alojan sitios de terceros: se compara el host completo
+// (acme.wixsite.com) en vez del dominio registrable.
+var sharedHosts = map[string]bool{
+	"wixsite.com": true, "blogspot.com": true, "github.io": true, "wordpress.com": true,
+	"webflow.io": true, "netlify.app": true, "vercel.app": true, "squarespace.com": true,
+}
+
+// socialHosts no identifican a una organización por dominio.
+var socialHosts = map[string]bool{
+	"facebook.com": true, "instagram.com": true, "linkedin.com": true, "twitter.com": true,
+	"x.com": true, "linktr.ee": true, "youtube.com": true, "google.com": true, "sites.google.com": true,
+}
+
+// registrableDomain extrae el dominio registrable de una URL
+// ("https://www.acme.com.ar/contacto" -> "acme.com.ar"). Es una heurística,
+// no la Public Suffix List completa.
+func registrableDomain(raw string) string {
+	host := urlHost(raw)
+	if host == "" {
+		return ""
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return ""
+	}
+	n := 2
+	if len(labels) >= 3 && len(labels[len(labels)-1]) == 2 && twoLevelSuffixes[labels[len(labels)-2]] {
+		n = 3
+	}
+	domain := strings.Join(labels[len(labels)-n:], ".")
+	if socialHosts[domain] || socialHosts[host] {
+		return ""
+	}
+	if sharedHosts[domain] {
+		return host
+	}
+	return domain
+}
+
+// linkedInKey normaliza una URL de LinkedIn a "company/acme".
+func linkedInKey(raw string) string {
+	host := urlHost(raw)
+	if host != "linkedin.com" && !strings.HasSuffix(host, ".linkedin.com") {
+		return ""
+	}
+	u, err := url.Parse(withScheme(raw))
+	if err != nil {
+		return ""
+	}
+	parts := strings.FieldsFunc(strings.ToLower(u.Path), func(r rune) bool { return r == '/' })
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+func urlHost(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(withScheme(raw))
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	return strings.TrimPrefix(host, "www.")
+}
+
+func withScheme(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		return "http://" + raw
+	}
+	return raw
+}
+
+// legalSuffixes son formas societarias que no distinguen organizaciones.
+var legalSuffixes = map[string]bool{
+	"sa": true, "srl": true, "sas": true, "sau": true, "sca": true, "sc": true,
+	"inc": true, "llc": true, "ltd": true, "ltda": true, "corp": true, "co": true,
+	"gmbh": true, "spa": true, "sl": true, "slu": true, "ag": true,
+}
+
+// normalizeOrgName pasa a minúscula sin acentos, quita puntuación y formas societarias.
+func normalizeOrgName(name string) string {
+	words := strings.FieldsFunc(foldText(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+	})
+	kept := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.ReplaceAll(w, ".", "")
+		if w == "" || legalSuffixes[w] {
+			continue
+		}
+		kept = append(kept, w)
+	}
+	return strings.Join(kept, " ")
+}
+
+// nameSimilarity es 1 - distancia de Levenshtein / largo del nombre más largo.
+func nameSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 0
+	}
+	// Si los largos ya difieren demasiado no hace falta calcular la distancia
+	if float64(min(len(ra), len(rb)))/float64(longest) < nameSimilarityThreshold {
+		return 0
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
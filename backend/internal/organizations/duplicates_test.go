@@ -0,0 +1,167 @@
+package organizations
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"https://www.acme.com/contacto", "acme.com"},
+		{"acme.com", "acme.com"},
+		{"WWW.ACME.COM.", "acme.com"},
+		{"http://shop.acme.io/?utm=1", "acme.io"},
+		{"https://www.acme.com.ar/", "acme.com.ar"},
+		{"tienda.acme.com.ar", "acme.com.ar"},
+		{"www.energia.gob.ar", "energia.gob.ar"},
+		{"acme.co.uk", "acme.co.uk"},
+		{"acme.ar", "acme.ar"},
+		{"https://acme.wixsite.com/site", "acme.wixsite.com"},
+		{"https://www.facebook.com/acme", ""},
+		{"sites.google.com/view/acme", ""},
+		{"localhost", ""},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := registrableDomain(tt.in); got != tt.want {
+			t.Errorf("registrableDomain(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLinkedInKey(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"https://www.linkedin.com/company/acme/", "company/acme"},
+		{"linkedin.com/company/Acme/about", "company/acme"},
+		{"https://ar.linkedin.com/company/acme?trk=x", "company/acme"},
+		{"https://www.linkedin.com/in/jdoe", "in/jdoe"},
+		{"https://www.linkedin.com/", ""},
+		{"https://example.com/company/acme", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := linkedInKey(tt.in); got != tt.want {
+			t.Errorf("linkedInKey(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeOrgName(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Acme S.A.", "acme"},
+		{"ACME SRL", "acme"},
+		{"Café Tech, Inc.", "cafe tech"},
+		{"Fundación Ñandú", "fundacion nandu"},
+		{"  Agro-Data  Ltda ", "agro data"},
+	}
+	for _, tt := range tests {
+		if got := normalizeOrgName(tt.in); got != tt.want {
+			t.Errorf("normalizeOrgName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		atLeast bool // >= nameSimilarityThreshold
+	}{
+		{"acme agro", "acme agro", true},
+		{"acme agro", "acme agros", true},     // 1 - 1/10
+		{"agrotech sur", "agrotec sur", true}, // 1 - 1/12
+		{"acme", "acne", false},               // 1 - 1/4
+		{"acme", "acme agro", false},          // largos muy distintos
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		sim := nameSimilarity(tt.a, tt.b)
+		if got := sim >= nameSimilarityThreshold; got != tt.atLeast {
+			t.Errorf("nameSimilarity(%q, %q) = %.2f, want >= threshold: %v", tt.a, tt.b, sim, tt.atLeast)
+		}
+	}
+	if got := levenshtein([]rune("kitten"), []rune("sitting")); got != 3 {
+		t.Errorf("levenshtein(kitten, sitting) = %d, want 3", got)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	records := []duplicateRecord{
+		{ID: "same-domain", Name: "Something Else", Country: "Chile", Website: strPtr("http://acme.com.ar/about")},
+		{ID: "near-name-other-country", Name: "Acme Agros", Country: "Uruguay"},
+		{ID: "near-name-same-country", Name: "Acme Agros S.A.", Country: "argentina"},
+		{ID: "same-linkedin", Name: "Unrelated", Country: "Peru", LinkedInURL: strPtr("linkedin.com/company/acme-agro")},
+		{ID: "self", Name: "Acme Agro", Country: "Argentina"},
+		{ID: "different", Name: "Beta Labs", Country: "Argentina", Website: strPtr("betalabs.com")},
+	}
+	org := &Organization{
+		ID:          "self",
+		Name:        "Acme Agro",
+		Country:     "Argentina",
+		Website:     strPtr("https://www.acme.com.ar"),
+		LinkedInURL: strPtr("https://www.linkedin.com/company/acme-agro/"),
+	}
+
+	got := make(map[string][]string)
+	for _, c := range findDuplicates(org, records) {
+		got[c.ID] = c.Reasons
+	}
+	want := map[string][]string{
+		"same-domain":            {reasonWebsite},
+		"near-name-same-country": {reasonName},
+		"same-linkedin":          {reasonLinkedIn},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates = %v, want %v", got, want)
+	}
+}
+
+func TestFindDuplicatesOrdersByScore(t *testing.T) {
+	records := []duplicateRecord{
+		{ID: "name", Name: "Acme Agros", Country: "AR"},
+		{ID: "domain", Name: "Other", Country: "AR", Website: strPtr("acme.com")},
+	}
+	candidates := findDuplicates(&Organization{Name: "Acme Agro", Country: "AR", Website: strPtr("acme.com")}, records)
+	if len(candidates) != 2 || candidates[0].ID != "domain" {
+		t.Fatalf("candidates = %+v, want domain match first", candidates)
+	}
+	if candidates[1].Score >= 1 || candidates[1].Score < nameSimilarityThreshold {
+		t.Errorf("name match score = %v", candidates[1].Score)
+	}
+}
+
+func TestClusterDuplicates(t *testing.T) {
+	records := []duplicateRecord{
+		{ID: "a", Name: "Acme Agro", Country: "AR", Website: strPtr("acme.com")},
+		{ID: "b", Name: "Totally Different", Country: "CL", Website: strPtr("https://www.acme.com/es"), LinkedInURL: strPtr("linkedin.com/company/acme")},
+		{ID: "c", Name: "Third", Country: "PE", LinkedInURL: strPtr("https://ar.linkedin.com/company/acme")},
+		{ID: "d", Name: "Acme Agros", Country: "UY"}, // nombre parecido, otro país
+		{ID: "e", Name: "Beta Labs", Country: "AR"},
+		{ID: "f", Name: "Beta Labs S.R.L.", Country: "AR"},
+		{ID: "g", Name: "Solo", Country: "AR"},
+	}
+	clusters := clusterDuplicates(records)
+
+	type summary struct {
+		IDs     []string
+		Reasons []string
+	}
+	got := make([]summary, 0, len(clusters))
+	for _, c := range clusters {
+		s := summary{Reasons: c.Reasons}
+		for _, m := range c.Members {
+			s.IDs = append(s.IDs, m.ID)
+		}
+		sort.Strings(s.IDs)
+		got = append(got, s)
+	}
+	want := []summary{
+		{IDs: []string{"a", "b", "c"}, Reasons: []string{reasonLinkedIn, reasonWebsite}},
+		{IDs: []string{"e", "f"}, Reasons: []string{reasonName}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("clusters = %+v, want %+v", got, want)
+	}
+}
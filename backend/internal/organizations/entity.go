@@ -15,6 +15,7 @@ const (
 // Organization es la entidad única alineada a los requerimientos del proyecto.
 type Organization struct {
 	ID               string             `json:"id"`
+	Slug             string             `json:"slug,omitempty"`
 	Name             string             `json:"name"`
 	OrganizationType string             `json:"organizationType"`
 	SectorPrimary    string             `json:"sectorPrimary"`
@@ -31,6 +32,7 @@ type Organization struct {
 	UpdatedAt        time.Time          `json:"updatedAt"`
 	Lat              *float64           `json:"lat,omitempty"`
 	Lng              *float64           `json:"lng,omitempty"`
+	Version          int                `json:"version"`
 
 	// --- Nuevos campos alineados al Word ---
 	Description  *string `json:"description,omitempty"`
@@ -46,4 +48,17 @@ type Organization struct {
 	Technology []string `json:"technology,omitempty"`
 	ImpactArea []string `json:"impactArea,omitempty"`
 	Badge      []string `json:"badge,omitempty"`
+
+	// Publicación programada: el scheduler publica (desde IN_REVIEW) en
+	// PublishAt y archiva en UnpublishAt. Cada uno se consume al ejecutarse;
+	// si en ese momento no se puede (p.ej. sigue en DRAFT), el motivo queda
+	// en ScheduleError.
+	PublishAt     *time.Time `json:"publishAt,omitempty"`
+	UnpublishAt   *time.Time `json:"unpublishAt,omitempty"`
+	ScheduleError *string    `json:"scheduleError,omitempty"` // último fallo del scheduler (solo lectura)
+
+	// --- Campos calculados (solo lectura, no se persisten) ---
+	Relevance  *float64          `json:"relevance,omitempty"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+	DistanceKm *float64          `json:"distanceKm,omitempty"`
 }
@@ -0,0 +1,153 @@
+package organizations
+
+import (
+	"backend/internal/spreadsheet"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportFormats asocia cada formato de exportación con su Content-Type.
+var exportFormats = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"xlsx":   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"ndjson": "application/x-ndjson",
+}
+
+// exportHeader son las columnas de CSV/XLSX. Usa los nombres de la API para
+// que el archivo pueda volver a importarse.
+func exportHeader() []string {
+	header := []string{"id"}
+	for _, f := range editableFields {
+		header = append(header, f.Name)
+	}
+	return append(header, "status", "createdAt", "updatedAt")
+}
+
+// exportRow aplana una organización en celdas de texto. Los campos
+// multi-valor se unen con "; " (el mismo separador que acepta Import).
+func exportRow(org *Organization) []string {
+	row := []string{org.ID}
+	for _, f := range editableFields {
+		if values, ok := multiValues(org, f.Name); ok {
+			row = append(row, strings.Join(values, "; "))
+			continue
+		}
+		if v := valueString(f.Value(org)); v != nil {
+			row = append(row, *v)
+		} else {
+			row = append(row, "")
+		}
+	}
+	return append(row,
+		string(org.Status),
+		org.CreatedAt.UTC().Format(time.RFC3339),
+		org.UpdatedAt.UTC().Format(time.RFC3339),
+	)
+}
+
+func multiValues(org *Organization, field string) ([]string, bool) {
+	switch field {
+	case "tags":
+		return org.Tags, true
+	case "technology":
+		return org.Technology, true
+	case "impactArea":
+		return org.ImpactArea, true
+	case "badge":
+		return org.Badge, true
+	}
+	return nil, false
+}
+
+// csvSafe evita que Excel interprete una celda de texto como fórmula. Un
+// número negativo (lat/lng) no es fórmula y se deja tal cual.
+func csvSafe(cell string) string {
+	if cell == "" || !strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return cell
+	}
+	if cell[0] == '-' {
+		if _, err := strconv.ParseFloat(cell, 64); err == nil {
+			return cell
+		}
+	}
+	return "'" + cell
+}
+
+// exportOrganizations escribe en w las organizaciones filtradas por params en
+// el formato pedido, fila por fila a medida que salen de la base.
+func (h *Handler) exportOrganizations(w http.ResponseWriter, params map[string]string, format string) {
+	contentType, ok := exportFormats[format]
+	if !ok {
+		http.Error(w, "format must be one of: csv, xlsx, ndjson", http.StatusBadRequest)
+		return
+	}
+
+	filename := fmt.Sprintf("organizations-%s.%s", time.Now().Format("20060102"), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	var err error
+	switch format {
+	case "csv":
+		err = h.exportCSV(w, params)
+	case "xlsx":
+		err = h.exportXLSX(w, params)
+	case "ndjson":
+		err = h.exportNDJSON(w, params)
+	}
+	if err != nil {
+		// Los encabezados ya se enviaron: solo podemos cortar la respuesta
+		log.Printf("export %s failed: %v", format, err)
+	}
+}
+
+func (h *Handler) exportCSV(w io.Writer, params map[string]string) error {
+	io.WriteString(w, "\xef\xbb\xbf") // BOM para que Excel detecte UTF-8
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportHeader()); err != nil {
+		return err
+	}
+	err := h.Repo.StreamFiltered(params, func(org *Organization) error {
+		row := exportRow(org)
+		for i := range row {
+			row[i] = csvSafe(row[i])
+		}
+		return cw.Write(row)
+	})
+	cw.Flush()
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func (h *Handler) exportXLSX(w io.Writer, params map[string]string) error {
+	xw, err := spreadsheet.NewXLSXWriter(w, "Organizations")
+	if err != nil {
+		return err
+	}
+	if err := xw.WriteRow(exportHeader()); err != nil {
+		return err
+	}
+	err = h.Repo.StreamFiltered(params, func(org *Organization) error {
+		return xw.WriteRow(exportRow(org))
+	})
+	if err != nil {
+		return err
+	}
+	return xw.Close()
+}
+
+func (h *Handler) exportNDJSON(w io.Writer, params map[string]string) error {
+	encoder := json.NewEncoder(w)
+	return h.Repo.StreamFiltered(params, func(org *Organization) error {
+		return encoder.Encode(org)
+	})
+}
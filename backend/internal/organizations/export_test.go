@@ -0,0 +1,25 @@
+package organizations
+
+import "testing"
+
+func TestCSVSafe(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{"Acme", "Acme"},
+		{"a=b", "a=b"},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+54 11 5555", "'+54 11 5555"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"-2+3+cmd|' /C calc'!A0", "'-2+3+cmd|' /C calc'!A0"},
+		{"-1+1", "'-1+1"},
+		{"\t=1+1", "'\t=1+1"},
+		{"\r=1+1", "'\r=1+1"},
+		{"-34.6037", "-34.6037"},
+		{"-58", "-58"},
+	}
+	for _, tt := range tests {
+		if got := csvSafe(tt.in); got != tt.want {
+			t.Errorf("csvSafe(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package organizations
+
+import "strings"
+
+// negatedPrefix marca un filtro de exclusión: ?not_country=Chile.
+// queryParams también traduce la forma ?country!=Chile a este prefijo.
+const negatedPrefix = "not_"
+
+// scalarFilter es una dimensión de una sola columna que admite selección
+// múltiple (?country=Argentina,Chile o parámetros repetidos) y exclusión.
+type scalarFilter struct {
+	Param  string
+	Column string
+}
+
+var scalarFilters = []scalarFilter{
+	{Param: "status", Column: "status"},
+	{Param: "country", Column: "country"},
+	{Param: "region", Column: "region"},
+	{Param: "city", Column: "city"},
+	{Param: "sectorPrimary", Column: "sector_primary"},
+	{Param: "sectorSecondary", Column: "sector_secondary"},
+	{Param: "organizationType", Column: "organization_type"},
+	{Param: "stage", Column: "stage"},
+	{Param: "outcomeStatus", Column: "outcome_status"},
+}
+
+// filterValues separa los valores de un filtro por coma, sin vacíos.
+func filterValues(s string) []string {
+	parts := strings.Split(s, ",")
+	values := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			values = append(values, p)
+		}
+	}
+	return values
+}
+
+// scalarCondition arma "= ?" / "IN (...)" para el filtro y "NOT IN" para su
+// forma negada. La exclusión conserva las filas con la columna en NULL.
+func scalarCondition(f scalarFilter, params map[string]string) (string, []interface{}) {
+	var query string
+	args := make([]interface{}, 0)
+
+	if values := filterValues(params[f.Param]); len(values) == 1 {
+		query += " AND " + f.Column + " = ?"
+		args = append(args, values[0])
+	} else if len(values) > 1 {
+		query += " AND " + f.Column + " IN (" + placeholders(len(values)) + ")"
+		args = appendStrings(args, values)
+	}
+
+	if values := filterValues(params[negatedPrefix+f.Param]); len(values) > 0 {
+		query += " AND (" + f.Column + " IS NULL OR " + f.Column + " NOT IN (" + placeholders(len(values)) + "))"
+		args = appendStrings(args, values)
+	}
+	return query, args
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func appendStrings(args []interface{}, values []string) []interface{} {
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return args
+}
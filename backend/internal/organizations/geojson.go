@@ -0,0 +1,104 @@
+package organizations
+
+import (
+	"net/http"
+	"strings"
+)
+
+// geoJSONContentType es el media type de RFC 7946.
+const geoJSONContentType = "application/geo+json"
+
+// FeatureCollection es un GeoJSON FeatureCollection (RFC 7946).
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature es un punto con las propiedades públicas de una organización.
+type Feature struct {
+	Type       string         `json:"type"`
+	ID         string         `json:"id"`
+	Geometry   PointGeometry  `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// PointGeometry usa el orden [lng, lat] que exige RFC 7946.
+type PointGeometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// wantsGeoJSON informa si el cliente pidió GeoJSON (?format=geojson o Accept).
+func wantsGeoJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "geojson" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), geoJSONContentType)
+}
+
+// toFeatureCollection convierte organizaciones en features; las que no
+// tienen coordenadas se omiten.
+func toFeatureCollection(orgs []Organization) FeatureCollection {
+	fc := FeatureCollection{Type: "FeatureCollection", Features: make([]Feature, 0, len(orgs))}
+	for i := range orgs {
+		org := &orgs[i]
+		if org.Lat == nil || org.Lng == nil {
+			continue
+		}
+		fc.Features = append(fc.Features, Feature{
+			Type: "Feature",
+			ID:   org.ID,
+			Geometry: PointGeometry{
+				Type:        "Point",
+				Coordinates: [2]float64{*org.Lng, *org.Lat},
+			},
+			Properties: featureProperties(org),
+		})
+	}
+	return fc
+}
+
+// featureProperties es el subconjunto de campos que viaja en cada feature:
+// lo necesario para el mapa y para filtrar en herramientas GIS.
+func featureProperties(org *Organization) map[string]any {
+	props := map[string]any{
+		"id":               org.ID,
+		"name":             org.Name,
+		"organizationType": org.OrganizationType,
+		"sectorPrimary":    org.SectorPrimary,
+		"outcomeStatus":    org.OutcomeStatus,
+		"country":          org.Country,
+		"region":           org.Region,
+		"city":             org.City,
+	}
+	optional := map[string]*string{
+		"sectorSecondary": org.SectorSecondary,
+		"stage":           org.Stage,
+		"website":         org.Website,
+		"logoUrl":         org.LogoURL,
+	}
+	for k, v := range optional {
+		if v != nil {
+			props[k] = *v
+		}
+	}
+	if org.YearFounded != nil {
+		props["yearFounded"] = *org.YearFounded
+	}
+	if org.Slug != "" {
+		props["slug"] = org.Slug
+	}
+	if org.DistanceKm != nil {
+		props["distanceKm"] = *org.DistanceKm
+	}
+	if len(org.Technology) > 0 {
+		props["technology"] = org.Technology
+	}
+	if len(org.ImpactArea) > 0 {
+		props["impactArea"] = org.ImpactArea
+	}
+	if len(org.Badge) > 0 {
+		props["badge"] = org.Badge
+	}
+	return props
+}
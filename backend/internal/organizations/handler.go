@@ -2,7 +2,13 @@ package organizations
 
 import (
 	"backend/internal/geocoding"
+	"backend/internal/spreadsheet"
+	"backend/internal/tiles"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -12,13 +18,15 @@ type Handler struct {
 	Service  *Service
 	Repo     *Repository
 	Geocoder *geocoding.NominatimClient
+	Tiles    *tiles.Cache
 }
 
-func NewHandler(service *Service, repo *Repository, geocoder *geocoding.NominatimClient) *Handler {
+func NewHandler(service *Service, repo *Repository, geocoder *geocoding.NominatimClient, tileCache *tiles.Cache) *Handler {
 	return &Handler{
 		Service:  service,
 		Repo:     repo,
 		Geocoder: geocoder,
+		Tiles:    tileCache,
 	}
 }
 
@@ -40,7 +48,15 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.Service.Create(&org); err != nil {
+	allowDuplicate := r.URL.Query().Get("allowDuplicate") == "true"
+	if err := h.Service.Create(&org, allowDuplicate, actorFromRequest(r)); err != nil {
+		if writeDuplicateError(w, err) {
+			return
+		}
+		if isValidationError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -75,15 +91,92 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.Service.Update(&org); err != nil {
+	ch, err := changeFromRequest(r)
+	if writeChangeError(w, err) {
+		return
+	}
+
+	rev, err := h.Service.Update(&org, ch)
+	if err != nil {
+		if writeChangeError(w, err) {
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if rev != nil {
+		writeRevisionAccepted(w, rev)
+		return
+	}
+
+	w.Header().Set("ETag", etag(&org))
+	w.Header().Set("Content-Type", "application/json")
+	encodeJSON(w, org)
+}
+
+// Patch aplica un JSON Merge Patch (RFC 7396): solo cambian los campos enviados
+// y null borra el valor.
+func (h *Handler) Patch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := extractID(r.URL.Path)
+	if id == "" {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	ct := r.Header.Get("Content-Type")
+	if ct != "" && !strings.HasPrefix(ct, "application/merge-patch+json") && !strings.HasPrefix(ct, "application/json") {
+		http.Error(w, "Content-Type must be application/merge-patch+json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var patch map[string]any
+	if err := decodeJSON(r, &patch); err != nil {
+		http.Error(w, "Invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ch, err := changeFromRequest(r)
+	if writeChangeError(w, err) {
+		return
+	}
+
+	org, rev, err := h.Service.Patch(id, patch, ch)
+	if err != nil {
+		if writeChangeError(w, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "no rows") {
+			http.Error(w, "Organization not found", http.StatusNotFound)
+		} else if isValidationError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if rev != nil {
+		writeRevisionAccepted(w, rev)
+		return
+	}
 
+	w.Header().Set("ETag", etag(org))
 	w.Header().Set("Content-Type", "application/json")
 	encodeJSON(w, org)
 }
 
+// writeRevisionAccepted responde 202 con la revisión que quedó pendiente:
+// la organización publicada no cambió (ni su ETag).
+func writeRevisionAccepted(w http.ResponseWriter, rev *Revision) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	encodeJSON(w, rev)
+}
+
 // GetByID devuelve el detalle admin de una organización (sin importar status).
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 	id := extractID(r.URL.Path)
@@ -98,6 +191,13 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// El ETag se envía luego en If-Match al modificar
+	w.Header().Set("ETag", etag(org))
+	if r.Header.Get("If-None-Match") == etag(org) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	encodeJSON(w, org)
 }
@@ -117,7 +217,15 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	force := r.URL.Query().Get("force") == "true"
 
-	if err := h.Service.Delete(id, force); err != nil {
+	ch, err := changeFromRequest(r)
+	if writeChangeError(w, err) {
+		return
+	}
+
+	if err := h.Service.Delete(id, force, ch); err != nil {
+		if writeChangeError(w, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "force=true") {
 			http.Error(w, err.Error(), http.StatusConflict) // 409
 		} else if strings.Contains(err.Error(), "not found") {
@@ -148,7 +256,15 @@ func (h *Handler) SubmitForReview(w http.ResponseWriter, r *http.Request) {
 
 	id := parts[1]
 
-	if err := h.Service.SubmitForReview(id); err != nil {
+	ch, err := changeFromRequest(r)
+	if writeChangeError(w, err) {
+		return
+	}
+
+	if err := h.Service.SubmitForReview(id, ch); err != nil {
+		if writeChangeError(w, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "no rows") {
 			http.Error(w, err.Error(), http.StatusNotFound)
 		} else {
@@ -177,7 +293,15 @@ func (h *Handler) Publish(w http.ResponseWriter, r *http.Request) {
 
 	id := parts[1]
 
-	if err := h.Service.Publish(id); err != nil {
+	ch, err := changeFromRequest(r)
+	if writeChangeError(w, err) {
+		return
+	}
+
+	if err := h.Service.Publish(id, ch); err != nil {
+		if writeChangeError(w, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "no rows") {
 			http.Error(w, err.Error(), http.StatusNotFound)
 		} else if strings.Contains(err.Error(), "validation") || strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "must be") {
@@ -208,7 +332,261 @@ func (h *Handler) Archive(w http.ResponseWriter, r *http.Request) {
 
 	id := parts[1]
 
-	if err := h.Service.Archive(id); err != nil {
+	ch, err := changeFromRequest(r)
+	if writeChangeError(w, err) {
+		return
+	}
+
+	if err := h.Service.Archive(id, ch); err != nil {
+		if writeChangeError(w, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "no rows") {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Reject devuelve una organización en revisión a DRAFT. Body: {"reason": "..."}.
+func (h *Handler) Reject(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /organizations/{id}/reject
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	if len(parts) < 2 {
+		http.Error(w, "invalid URL", http.StatusBadRequest)
+		return
+	}
+
+	id := parts[1]
+
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		http.Error(w, "Invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ch, err := changeFromRequest(r)
+	if writeChangeError(w, err) {
+		return
+	}
+	ch.Reason = body.Reason
+
+	if err := h.Service.Reject(id, ch); err != nil {
+		if writeChangeError(w, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "no rows") {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Merge fusiona otra organización en esta. Body: {"sourceId": "...",
+// "fields": {"name": "source", ...}}. If-Match aplica al destino. Si el
+// destino está publicado responde 202 con la revisión que lleva los campos.
+func (h *Handler) Merge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /organizations/{id}/merge
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 {
+		http.Error(w, "invalid URL", http.StatusBadRequest)
+		return
+	}
+	id := parts[1]
+
+	var req MergeRequest
+	if err := decodeJSON(r, &req); err != nil {
+		http.Error(w, "Invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ch, err := changeFromRequest(r)
+	if writeChangeError(w, err) {
+		return
+	}
+
+	org, rev, err := h.Service.Merge(id, req, ch)
+	if err != nil {
+		if writeChangeError(w, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "no rows") {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else if isValidationError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if rev != nil {
+		// El destino está publicado: el origen ya se borró y los campos esperan aprobación
+		writeRevisionAccepted(w, rev)
+		return
+	}
+
+	w.Header().Set("ETag", etag(org))
+	w.Header().Set("Content-Type", "application/json")
+	encodeJSON(w, org)
+}
+
+// Versions atiende los snapshots de una organización:
+//
+//	GET  /organizations/{id}/versions
+//	GET  /organizations/{id}/versions/diff?from=N[&to=M]  (sin to: contra el estado actual)
+//	GET  /organizations/{id}/versions/{n}
+//	POST /organizations/{id}/versions/{n}/restore         (If-Match opcional)
+func (h *Handler) Versions(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 3 || parts[2] != "versions" || len(parts) > 5 {
+		http.Error(w, "invalid URL", http.StatusBadRequest)
+		return
+	}
+	id := parts[1]
+
+	writeErr := func(err error) {
+		if writeChangeError(w, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "no rows") {
+			http.Error(w, "Not found: "+err.Error(), http.StatusNotFound)
+		} else if isValidationError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+
+	// POST .../versions/{n}/restore
+	if len(parts) == 5 {
+		if parts[4] != "restore" {
+			http.Error(w, "invalid URL", http.StatusBadRequest)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		n, err := strconv.Atoi(parts[3])
+		if err != nil || n < 1 {
+			http.Error(w, "invalid version", http.StatusBadRequest)
+			return
+		}
+		ch, err := changeFromRequest(r)
+		if writeChangeError(w, err) {
+			return
+		}
+		org, rev, err := h.Service.RestoreVersion(id, n, ch)
+		if err != nil {
+			writeErr(err)
+			return
+		}
+		if rev != nil {
+			writeRevisionAccepted(w, rev)
+			return
+		}
+		w.Header().Set("ETag", etag(org))
+		w.Header().Set("Content-Type", "application/json")
+		encodeJSON(w, org)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var data any
+	var err error
+	switch {
+	case len(parts) == 3:
+		data, err = h.Service.Versions(id)
+	case parts[3] == "diff":
+		from, ferr := strconv.Atoi(r.URL.Query().Get("from"))
+		to := 0
+		if s := r.URL.Query().Get("to"); s != "" {
+			to, err = strconv.Atoi(s)
+		}
+		if ferr != nil || err != nil || from < 1 || to < 0 {
+			http.Error(w, "from and to must be version numbers", http.StatusBadRequest)
+			return
+		}
+		data, err = h.Service.DiffVersions(id, from, to)
+	default:
+		n, nerr := strconv.Atoi(parts[3])
+		if nerr != nil || n < 1 {
+			http.Error(w, "invalid version", http.StatusBadRequest)
+			return
+		}
+		data, err = h.Service.Version(id, n)
+	}
+	if err != nil {
+		writeErr(err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encodeJSON(w, data)
+}
+
+// Restore devuelve una organización archivada a DRAFT. Body opcional: {"reason": "..."}.
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /organizations/{id}/restore
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	if len(parts) < 2 {
+		http.Error(w, "invalid URL", http.StatusBadRequest)
+		return
+	}
+
+	id := parts[1]
+
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := decodeJSON(r, &body); err != nil {
+			http.Error(w, "Invalid body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ch, err := changeFromRequest(r)
+	if writeChangeError(w, err) {
+		return
+	}
+	ch.Reason = body.Reason
+
+	if err := h.Service.Restore(id, ch); err != nil {
+		if writeChangeError(w, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "no rows") {
 			http.Error(w, err.Error(), http.StatusNotFound)
 		} else {
@@ -238,25 +616,34 @@ func extractID(path string) string {
 	return ""
 }
 
-// ListPublic devuelve solo organizaciones publicadas (para el mapa)
+// ListPublic devuelve solo organizaciones publicadas (para el mapa).
+// Con ?format=geojson o Accept: application/geo+json responde un FeatureCollection.
+// Pagina con ?limit= y ?cursor=; el total y la página siguiente van en headers.
+// Con ?near=lat,lng[&radiusKm=] filtra por distancia y ?sort=distance ordena por cercanía.
 func (h *Handler) ListPublic(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	qp := r.URL.Query()
-	params := make(map[string]string)
-	for k, v := range qp {
-		if len(v) > 0 {
-			params[k] = v[0]
-		}
-	}
+	params := queryParams(r)
 	params["status"] = string(StatusPublished)
+	geoJSON := wantsGeoJSON(r)
+	if geoJSON {
+		// Sin coordenadas no hay feature: que tampoco cuenten en el total ni en la página
+		params["onlyMappable"] = "true"
+	}
 
-	orgs, err := h.Repo.FindFiltered(params)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	orgs, ok := h.findPage(w, r, params, publicPageLimits)
+	if !ok {
+		return
+	}
+
+	// GeoJSON (RFC 7946) para el mapa y herramientas GIS
+	w.Header().Add("Vary", "Accept")
+	if geoJSON {
+		w.Header().Set("Content-Type", geoJSONContentType)
+		encodeJSON(w, toFeatureCollection(orgs))
 		return
 	}
 
@@ -264,24 +651,23 @@ func (h *Handler) ListPublic(w http.ResponseWriter, r *http.Request) {
 	encodeJSON(w, orgs)
 }
 
-// Aggregates devuelve los filtros dinámicos y sus conteos.
+// Aggregates devuelve los filtros dinámicos y sus conteos (solo publicadas).
 func (h *Handler) Aggregates(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	qp := r.URL.Query()
-	params := make(map[string]string)
-	for k, v := range qp {
-		if len(v) > 0 {
-			params[k] = v[0]
-		}
-	}
+	params := queryParams(r)
+	params["status"] = string(StatusPublished)
 
 	data, err := h.Repo.GetAggregates(params)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if isValidationError(err) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
 		return
 	}
 
@@ -302,11 +688,15 @@ func (h *Handler) GetPublicByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	org, err := h.Repo.FindPublishedByID(id)
+	org, redirect, err := findPublic(h.Repo, id)
 	if err != nil {
 		http.Error(w, "Not found", http.StatusNotFound)
 		return
 	}
+	if redirect != "" {
+		http.Redirect(w, r, "/public/organizations/"+redirect, http.StatusMovedPermanently)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	encodeJSON(w, org)
@@ -319,17 +709,10 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	qp := r.URL.Query()
-	params := make(map[string]string)
-	for k, v := range qp {
-		if len(v) > 0 {
-			params[k] = v[0]
-		}
-	}
+	params := queryParams(r)
 
-	orgs, err := h.Repo.FindFiltered(params)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	orgs, ok := h.findPage(w, r, params, adminPageLimits)
+	if !ok {
 		return
 	}
 
@@ -337,6 +720,33 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	encodeJSON(w, orgs)
 }
 
+// findPage resuelve la página pedida y escribe sus headers; si falla
+// responde el error y devuelve false.
+func (h *Handler) findPage(w http.ResponseWriter, r *http.Request, params map[string]string, limits pageLimits) ([]Organization, bool) {
+	req, err := parsePageRequest(params, limits)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if _, err := parseNear(params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	page, err := h.Repo.FindPage(params, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if isValidationError(err) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return nil, false
+	}
+
+	writePageHeaders(w, r, page)
+	return page.Items, true
+}
+
 // Geocode busca coordenadas para una organización existente.
 func (h *Handler) Geocode(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -358,6 +768,15 @@ func (h *Handler) Geocode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Validamos la precondición antes de consultar el servicio externo
+	ch, err := changeFromRequest(r)
+	if err == nil {
+		err = ch.checkVersion(org)
+	}
+	if writeChangeError(w, err) {
+		return
+	}
+
 	lat, lng, err := h.Geocoder.Geocode(org.City, org.Region, org.Country)
 	if err != nil {
 		if err.Error() == "no results found" {
@@ -368,12 +787,20 @@ func (h *Handler) Geocode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.Repo.UpdateCoordinates(id, lat, lng); err != nil {
+	updatedOrg, rev, err := h.Service.UpdateCoordinates(id, lat, lng, "GEOCODE", ch)
+	if err != nil {
+		if writeChangeError(w, err) {
+			return
+		}
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if rev != nil {
+		writeRevisionAccepted(w, rev)
+		return
+	}
 
-	updatedOrg, _ := h.Repo.FindByID(id)
+	w.Header().Set("ETag", etag(updatedOrg))
 	w.Header().Set("Content-Type", "application/json")
 	encodeJSON(w, updatedOrg)
 }
@@ -402,12 +829,436 @@ func (h *Handler) PatchCoordinates(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.Repo.UpdateCoordinates(id, coords.Lat, coords.Lng); err != nil {
-		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+	ch, err := changeFromRequest(r)
+	if writeChangeError(w, err) {
+		return
+	}
+
+	updatedOrg, rev, err := h.Service.UpdateCoordinates(id, coords.Lat, coords.Lng, "UPDATE_COORDINATES", ch)
+	if err != nil {
+		if writeChangeError(w, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "no rows") {
+			http.Error(w, "Organization not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if rev != nil {
+		writeRevisionAccepted(w, rev)
 		return
 	}
 
-	updatedOrg, _ := h.Repo.FindByID(id)
+	w.Header().Set("ETag", etag(updatedOrg))
 	w.Header().Set("Content-Type", "application/json")
 	encodeJSON(w, updatedOrg)
 }
+
+// maxImportSize limita el tamaño de los archivos de importación.
+const maxImportSize = 10 << 20
+
+// Import importa organizaciones desde CSV o XLSX (multipart "file" o body crudo).
+// Por defecto es dry-run: solo devuelve el reporte por fila. Con ?commit=true
+// crea todas las filas válidas como DRAFT en una única transacción.
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var data []byte
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, ferr := r.FormFile("file")
+		if ferr != nil {
+			http.Error(w, "missing file: "+ferr.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		data, err = io.ReadAll(file)
+	} else {
+		data, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		http.Error(w, "could not read file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := spreadsheet.Read(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("commit") != "true"
+	allowDuplicate := r.URL.Query().Get("allowDuplicate") == "true"
+	report, err := h.Service.Import(rows, dryRun, allowDuplicate, actorFromRequest(r))
+	if err != nil {
+		if isValidationError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if report.Created > 0 {
+		w.WriteHeader(http.StatusCreated)
+	}
+	encodeJSON(w, report)
+}
+
+// Duplicates lista los grupos de organizaciones que parecen duplicadas
+// (mismo dominio, misma página de LinkedIn o nombre parecido en el mismo país).
+func (h *Handler) Duplicates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	clusters, err := h.Service.DuplicateClusters()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encodeJSON(w, map[string]any{
+		"total":    len(clusters),
+		"clusters": clusters,
+	})
+}
+
+// ExportPublic exporta organizaciones publicadas (?format=csv|xlsx|ndjson)
+// aceptando los mismos filtros que ListPublic.
+func (h *Handler) ExportPublic(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := queryParams(r)
+	params["status"] = string(StatusPublished)
+	h.exportOrganizations(w, params, params["format"])
+}
+
+// Export exporta organizaciones en cualquier estado (admin), con los mismos filtros que List.
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := queryParams(r)
+	h.exportOrganizations(w, params, params["format"])
+}
+
+// Clusters agrupa las organizaciones publicadas y mapeables visibles en bbox.
+// Ruta: /public/organizations/clusters?bbox=minLat,minLng,maxLat,maxLng&zoom=N
+// Acepta los mismos filtros que ListPublic.
+func (h *Handler) Clusters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := queryParams(r)
+	zoom, err := strconv.Atoi(params["zoom"])
+	if err != nil || zoom < 0 || zoom > 22 {
+		http.Error(w, "zoom must be an integer between 0 and 22", http.StatusBadRequest)
+		return
+	}
+	params["status"] = string(StatusPublished)
+	params["onlyMappable"] = "true"
+	delete(params, "limit")
+	delete(params, "offset")
+
+	points, err := h.Repo.FindMapPoints(params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := ClustersResponse{
+		Zoom:     zoom,
+		Total:    len(points),
+		Clusters: make([]Cluster, 0),
+		Points:   make([]MapPoint, 0),
+	}
+	if zoom >= clusterMaxZoom {
+		resp.Points = points
+	} else {
+		resp.Clusters = clusterPoints(points, zoom)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encodeJSON(w, resp)
+}
+
+// Timeseries devuelve la evolución del ecosistema publicado: fundaciones por
+// año (?metric=founded) o publicaciones por mes (?metric=published),
+// opcionalmente por ?groupBy=country|sectorPrimary|organizationType|...
+// Acepta los filtros del listado y ?format=csv.
+func (h *Handler) Timeseries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := queryParams(r)
+	q, err := parseTimeseriesQuery(params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if q.Metric == metricPublished {
+		// Lo que se publicó una vez cuenta en su mes aunque hoy esté archivado
+		delete(params, "status")
+	} else {
+		params["status"] = string(StatusPublished)
+	}
+
+	data, err := h.Repo.Timeseries(params, q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if params["format"] == "csv" {
+		w.Header().Set("Content-Type", exportFormats["csv"])
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="timeseries-%s.csv"`, q.Metric))
+		if err := writeTimeseriesCSV(w, data); err != nil {
+			log.Printf("timeseries: csv write failed: %v", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encodeJSON(w, data)
+}
+
+// SearchArea devuelve las organizaciones publicadas dentro de un GeoJSON
+// Polygon o MultiPolygon (body) y sus conteos. Acepta los mismos filtros
+// que el listado en el query string; ?limit= recorta solo los items.
+func (h *Handler) SearchArea(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 5<<20))
+	if err != nil {
+		http.Error(w, "geometry too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	shape, err := parseArea(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	params := queryParams(r)
+	req, err := parsePageRequest(params, publicPageLimits)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	bucket, err := parseYearBucket(params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params["status"] = string(StatusPublished)
+	params["onlyMappable"] = "true"
+	params["bbox"] = shape.bbox() // prefiltro en SQL; el polígono exacto se evalúa abajo
+	delete(params, "limit")
+	delete(params, "offset")
+
+	matched := make([]Organization, 0)
+	err = h.Repo.StreamFiltered(params, func(org *Organization) error {
+		if shape.contains(*org.Lat, *org.Lng) {
+			matched = append(matched, *org)
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := AreaSearchResponse{
+		Total:      len(matched),
+		Items:      matched[:min(len(matched), req.Limit)],
+		Aggregates: aggregateOrganizations(matched, bucket),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encodeJSON(w, resp)
+}
+
+// Tile devuelve un Mapbox Vector Tile con las organizaciones publicadas y
+// mapeables del tile. Ruta: /public/tiles/{z}/{x}/{y}.mvt
+// Acepta los mismos filtros que ListPublic (salvo bbox, que lo define el tile).
+func (h *Handler) Tile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var z, x, y int
+	path := strings.TrimPrefix(r.URL.Path, "/public/tiles/")
+	if _, err := fmt.Sscanf(path, "%d/%d/%d.mvt", &z, &x, &y); err != nil || !strings.HasSuffix(path, ".mvt") {
+		http.Error(w, "invalid tile path, expected /public/tiles/{z}/{x}/{y}.mvt", http.StatusBadRequest)
+		return
+	}
+	if err := tiles.ValidateCoords(z, x, y); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	key := fmt.Sprintf("%d/%d/%d?%s", z, x, y, r.URL.Query().Encode())
+	data, ok := h.Tiles.Get(key)
+	if !ok {
+		params := queryParams(r)
+		params["status"] = string(StatusPublished)
+		params["onlyMappable"] = "true"
+		minLat, minLng, maxLat, maxLng := tiles.Bounds(z, x, y)
+		params["bbox"] = fmt.Sprintf("%f,%f,%f,%f", minLat, minLng, maxLat, maxLng)
+		delete(params, "limit")
+		delete(params, "offset")
+
+		points, err := h.Repo.FindMapPoints(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		features := make([]tiles.Point, 0, len(points))
+		for _, p := range points {
+			features = append(features, tiles.Point{
+				Lat: p.Lat,
+				Lng: p.Lng,
+				Properties: map[string]string{
+					"id":               p.ID,
+					"name":             p.Name,
+					"organizationType": p.OrganizationType,
+					"sectorPrimary":    p.SectorPrimary,
+				},
+			})
+		}
+		data = tiles.Encode("organizations", z, x, y, features)
+		h.Tiles.Set(key, data)
+	}
+
+	etag := tiles.ETag(data)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.Tiles.TTL().Seconds())))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.mapbox-vector-tile")
+	w.Write(data)
+}
+
+// Revisions atiende las revisiones pendientes de organizaciones publicadas:
+//
+//	GET  /organizations/revisions[?status=IN_REVIEW]        (de todas las organizaciones)
+//	GET  /organizations/{id}/revisions[?status=DRAFT]
+//	GET  /organizations/{id}/revisions/{rid}
+//	GET  /organizations/{id}/revisions/{rid}/diff           (contra lo publicado hoy)
+//	POST /organizations/{id}/revisions/{rid}/review
+//	POST /organizations/{id}/revisions/{rid}/approve        (If-Match opcional)
+//	POST /organizations/{id}/revisions/{rid}/discard
+func (h *Handler) Revisions(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[len(parts)-1] == "revisions" && len(parts) <= 3 {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		id := ""
+		if len(parts) == 3 {
+			id = parts[1]
+		}
+		revisions, err := h.Service.Revisions(id, RevisionStatus(strings.ToUpper(r.URL.Query().Get("status"))))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encodeJSON(w, revisions)
+		return
+	}
+
+	if len(parts) < 4 || len(parts) > 5 || parts[2] != "revisions" {
+		http.Error(w, "invalid URL", http.StatusBadRequest)
+		return
+	}
+	id := parts[1]
+	revisionID, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil || revisionID < 1 {
+		http.Error(w, "invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	action := ""
+	if len(parts) == 5 {
+		action = parts[4]
+	}
+	wantMethod := http.MethodPost
+	if action == "" || action == "diff" {
+		wantMethod = http.MethodGet
+	}
+	if r.Method != wantMethod {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ch, err := changeFromRequest(r)
+	if writeChangeError(w, err) {
+		return
+	}
+
+	var data any
+	switch action {
+	case "":
+		data, err = h.Service.Revision(id, revisionID)
+	case "diff":
+		data, err = h.Service.DiffRevision(id, revisionID)
+	case "review":
+		data, err = h.Service.SubmitRevision(id, revisionID, ch)
+	case "discard":
+		data, err = h.Service.DiscardRevision(id, revisionID, ch)
+	case "approve":
+		var org *Organization
+		if org, err = h.Service.ApproveRevision(id, revisionID, ch); err == nil {
+			w.Header().Set("ETag", etag(org))
+			data = org
+		}
+	default:
+		http.Error(w, "invalid URL", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		if writeChangeError(w, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "no rows") {
+			http.Error(w, "Not found: "+err.Error(), http.StatusNotFound)
+		} else if isValidationError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encodeJSON(w, data)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 // decodeJSON decodifica el body JSON de un request HTTP.
@@ -29,3 +30,32 @@ func decodeJSON(r *http.Request, dst interface{}) error {
 func parseInt(s string) (int, error) {
 	return strconv.Atoi(s)
 }
+
+// actorFromRequest identifica quién realiza un cambio, para la auditoría.
+// El token de admin es compartido, por eso el cliente indica el usuario en X-Actor.
+func actorFromRequest(r *http.Request) string {
+	if actor := strings.TrimSpace(r.Header.Get("X-Actor")); actor != "" {
+		return actor
+	}
+	return "admin"
+}
+
+// queryParams aplana el query string: los parámetros repetidos se unen por
+// coma (?country=AR&country=CL equivale a ?country=AR,CL) y la forma
+// "country!=CL" se traduce a "not_country".
+func queryParams(r *http.Request) map[string]string {
+	params := make(map[string]string)
+	for k, v := range r.URL.Query() {
+		if len(v) == 0 {
+			continue
+		}
+		if strings.HasSuffix(k, "!") {
+			k = negatedPrefix + strings.TrimSuffix(k, "!")
+		}
+		if prev, ok := params[k]; ok {
+			v = append([]string{prev}, v...)
+		}
+		params[k] = strings.Join(v, ",")
+	}
+	return params
+}
@@ -0,0 +1,109 @@
+package organizations
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxSlugLen deja lugar para el sufijo de colisión dentro de VARCHAR(255).
+const maxSlugLen = 80
+
+// newUUIDv7 genera un UUID versión 7 (RFC 9562): 48 bits de timestamp en
+// milisegundos y el resto aleatorio, así los ids nuevos quedan ordenados por creación.
+func newUUIDv7() string {
+	var b [16]byte
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(time.Now().UnixMilli()))
+	copy(b[:6], ts[2:])
+	if _, err := rand.Read(b[6:]); err != nil {
+		panic(fmt.Sprintf("uuid: crypto/rand failed: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x70 // versión 7
+	b[8] = (b[8] & 0x3f) | 0x80 // variante RFC 4122
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// slugify arma un slug URL-safe a partir del nombre ("Agro Tech S.A." -> "agro-tech-s-a").
+func slugify(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range foldText(name) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimRight(sb.String(), "-")
+	if len(slug) > maxSlugLen {
+		slug = strings.TrimRight(slug[:maxSlugLen], "-")
+	}
+	if slug == "" {
+		return "organization"
+	}
+	return slug
+}
+
+// reservedSlugs son los segmentos fijos bajo /public/organizations/ y
+// /organizations/: una organización con ese slug quedaría tapada por la ruta.
+// Al agregar una ruta hermana hay que sumarla acá.
+var reservedSlugs = map[string]bool{
+	"aggregates": true,
+	"clusters":   true,
+	"duplicates": true,
+	"export":     true,
+	"import":     true,
+	"search":     true,
+}
+
+// uniqueSlug devuelve base o, si está tomado o reservado, base-2, base-3, ...
+// Un slug con forma de id también cuenta como tomado: GetPublicByID busca
+// primero por id, así que nunca se llegaría a él.
+func uniqueSlug(base string, taken map[string]bool) string {
+	if !taken[base] && !reservedSlugs[base] && !looksLikeID(base) {
+		return base
+	}
+	for n := 2; ; n++ {
+		if candidate := fmt.Sprintf("%s-%d", base, n); !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+// looksLikeID informa si s tiene la forma de un UUID (8-4-4-4-12 hex).
+func looksLikeID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdef", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isSlugConflict detecta la violación del índice único de slug (carrera
+// entre dos altas con el mismo nombre).
+func isSlugConflict(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "ux_organizations_slug")
+}
+
+// isOpenRevisionConflict detecta la violación del índice que admite una sola
+// revisión abierta por organización.
+func isOpenRevisionConflict(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "ux_organization_revisions_open")
+}
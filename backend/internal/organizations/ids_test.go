@@ -0,0 +1,56 @@
+package organizations
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Agro Tech S.A.", "agro-tech-s-a"},
+		{"  Fundación Ñandú  ", "fundacion-nandu"},
+		{"***", "organization"},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.in); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUniqueSlug(t *testing.T) {
+	tests := []struct {
+		base  string
+		taken map[string]bool
+		want  string
+	}{
+		{"acme", nil, "acme"},
+		{"acme", map[string]bool{"acme": true, "acme-2": true}, "acme-3"},
+		// Los segmentos de rutas públicas cuentan como colisión
+		{"search", nil, "search-2"},
+		{"export", map[string]bool{"export-2": true}, "export-3"},
+		{"aggregates", nil, "aggregates-2"},
+		{"clusters", nil, "clusters-2"},
+		// Un slug igual al id de otra organización quedaría tapado por ella
+		{"legacy-7", map[string]bool{"legacy-7": true}, "legacy-7-2"},
+		{"0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b", nil, "0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b-2"},
+	}
+	for _, tt := range tests {
+		if got := uniqueSlug(tt.base, tt.taken); got != tt.want {
+			t.Errorf("uniqueSlug(%q, %v) = %q, want %q", tt.base, tt.taken, got, tt.want)
+		}
+	}
+}
+
+func TestLooksLikeID(t *testing.T) {
+	tests := map[string]bool{
+		newUUIDv7():                              true,
+		"0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b":   true,
+		"0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b-2": false,
+		"acme-agro":                              false,
+		"0190a1b2xc3d4-7e5f-8a9b-0c1d2e3f4a5b":   false,
+		"0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5g":   false,
+	}
+	for in, want := range tests {
+		if got := looksLikeID(in); got != want {
+			t.Errorf("looksLikeID(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
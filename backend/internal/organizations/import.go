@@ -0,0 +1,295 @@
+package organizations
+
+import (
+	"backend/internal/audit"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ImportRowResult es el resultado de validar una fila de la planilla.
+// Row es el número de fila tal como lo ve el usuario (1 = encabezado).
+type ImportRowResult struct {
+	Row    int      `json:"row"`
+	ID     string   `json:"id,omitempty"`
+	Name   string   `json:"name,omitempty"`
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ImportReport resume una importación (dry-run o commit).
+type ImportReport struct {
+	DryRun         bool              `json:"dryRun"`
+	TotalRows      int               `json:"totalRows"`
+	ValidRows      int               `json:"validRows"`
+	InvalidRows    int               `json:"invalidRows"`
+	Created        int               `json:"created"`
+	UnknownColumns []string          `json:"unknownColumns,omitempty"`
+	Rows           []ImportRowResult `json:"rows"`
+}
+
+// Tipos de columna que no son texto simple.
+var (
+	importFloatFields = map[string]bool{"lat": true, "lng": true}
+	importIntFields   = map[string]bool{"yearFounded": true}
+	importMultiFields = map[string]bool{"tags": true, "technology": true, "impactArea": true, "badge": true}
+)
+
+// importColumns mapea encabezados normalizados al nombre del campo en la API.
+// Acepta tanto el nombre JSON ("sectorPrimary") como la columna de la DB
+// ("sector_primary", "tags_json" o "tags").
+var importColumns = buildImportColumns()
+
+func buildImportColumns() map[string]string {
+	cols := map[string]string{"id": "id"}
+	for _, f := range editableFields {
+		cols[normalizeHeader(f.Name)] = f.Name
+		cols[normalizeHeader(f.Column)] = f.Name
+		cols[normalizeHeader(strings.TrimSuffix(f.Column, "_json"))] = f.Name
+	}
+	return cols
+}
+
+// normalizeHeader deja solo letras y dígitos en minúscula ("Sector Primary" -> "sectorprimary").
+func normalizeHeader(h string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(h) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// splitMulti separa valores múltiples de una celda (";", "|" o ",").
+func splitMulti(cell string) []string {
+	parts := strings.FieldsFunc(cell, func(r rune) bool {
+		return r == ';' || r == '|' || r == ','
+	})
+	values := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			values = append(values, p)
+		}
+	}
+	return values
+}
+
+// rowToOrganization arma una Organization a partir de una fila, usando fields
+// (nombre de campo por columna; "" = columna ignorada).
+func rowToOrganization(fields []string, row []string) (*Organization, error) {
+	doc := make(map[string]any)
+	for i, field := range fields {
+		if field == "" || i >= len(row) {
+			continue
+		}
+		cell := strings.TrimSpace(row[i])
+		if cell == "" {
+			continue
+		}
+
+		switch {
+		case importMultiFields[field]:
+			doc[field] = splitMulti(cell)
+		case importFloatFields[field]:
+			v, err := strconv.ParseFloat(strings.Replace(cell, ",", ".", 1), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a number", field)
+			}
+			doc[field] = v
+		case importIntFields[field]:
+			v, err := strconv.ParseFloat(cell, 64)
+			if err != nil || v != float64(int(v)) {
+				return nil, fmt.Errorf("%s must be an integer", field)
+			}
+			doc[field] = int(v)
+		default:
+			doc[field] = cell
+		}
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var org Organization
+	if err := decoder.Decode(&org); err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// Import valida las filas de una planilla (la primera es el encabezado).
+// En dry-run solo devuelve el reporte; si no, crea todas las filas válidas
+// como DRAFT en una única transacción. Salvo allowDuplicate, las filas que
+// parecen duplicar una organización existente (u otra fila) son inválidas.
+func (s *Service) Import(rows [][]string, dryRun, allowDuplicate bool, actor string) (*ImportReport, error) {
+	if len(rows) == 0 {
+		return nil, invalid(fmt.Errorf("file is empty"))
+	}
+
+	report := &ImportReport{DryRun: dryRun, Rows: make([]ImportRowResult, 0, len(rows)-1)}
+
+	header := rows[0]
+	fields := make([]string, len(header))
+	seenFields := make(map[string]bool)
+	for i, h := range header {
+		field, ok := importColumns[normalizeHeader(h)]
+		if !ok {
+			if strings.TrimSpace(h) != "" {
+				report.UnknownColumns = append(report.UnknownColumns, h)
+			}
+			continue
+		}
+		if seenFields[field] {
+			return nil, invalid(fmt.Errorf("duplicate column for %s", field))
+		}
+		seenFields[field] = true
+		fields[i] = field
+	}
+	if !seenFields["name"] {
+		return nil, invalid(fmt.Errorf("missing required column: name"))
+	}
+
+	// 1. Convertir, normalizar y validar cada fila
+	candidates := make([]*Organization, 0, len(rows)-1)
+	results := make([]*ImportRowResult, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		if isBlankRow(row) {
+			continue
+		}
+		result := &ImportRowResult{Row: i + 2}
+
+		org, err := rowToOrganization(fields, row)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		} else {
+			if strings.TrimSpace(org.ID) == "" {
+				org.ID = newUUIDv7()
+			}
+			if err := Normalize(org); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			}
+			if err := s.ValidateTaxonomies(org); err != nil {
+				if !isValidationError(err) {
+					return nil, err
+				}
+				result.Errors = append(result.Errors, err.Error())
+			}
+			result.ID = org.ID
+			result.Name = org.Name
+			org.Status = StatusDraft
+		}
+
+		candidates = append(candidates, org)
+		results = append(results, result)
+	}
+
+	// 2. IDs repetidos dentro del archivo o ya existentes en la base
+	ids := make([]string, 0, len(candidates))
+	for _, org := range candidates {
+		if org != nil && org.ID != "" {
+			ids = append(ids, org.ID)
+		}
+	}
+	existing, err := s.repo.ExistingIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	firstRow := make(map[string]int)
+	for i, org := range candidates {
+		if org == nil || org.ID == "" {
+			continue
+		}
+		if existing[org.ID] {
+			results[i].Errors = append(results[i].Errors, "id already exists")
+		}
+		if prev, ok := firstRow[org.ID]; ok {
+			results[i].Errors = append(results[i].Errors, fmt.Sprintf("duplicate id (also in row %d)", prev))
+		} else {
+			firstRow[org.ID] = results[i].Row
+		}
+	}
+
+	// 3. Posibles duplicados (contra la base y contra filas anteriores)
+	if !allowDuplicate {
+		records, err := s.repo.DuplicateRecords()
+		if err != nil {
+			return nil, err
+		}
+		for i, org := range candidates {
+			if org == nil {
+				continue
+			}
+			for _, c := range findDuplicates(org, records) {
+				results[i].Errors = append(results[i].Errors,
+					fmt.Sprintf("possible duplicate of %s (%s): %s", c.ID, c.Name, strings.Join(c.Reasons, ", ")))
+			}
+			records = append(records, *recordOf(org))
+		}
+	}
+
+	// 4. Reporte
+	valid := make([]*Organization, 0, len(candidates))
+	for i, result := range results {
+		result.Valid = len(result.Errors) == 0
+		if result.Valid {
+			valid = append(valid, candidates[i])
+			report.ValidRows++
+		} else {
+			report.InvalidRows++
+		}
+		report.Rows = append(report.Rows, *result)
+	}
+	report.TotalRows = len(results)
+
+	if dryRun || len(valid) == 0 {
+		return report, nil
+	}
+
+	// 5. Commit: todas las filas válidas o ninguna
+	batchSlugs := make(map[string]bool, len(valid))
+	for _, org := range valid {
+		if err := s.assignSlug(org, batchSlugs); err != nil {
+			return nil, err
+		}
+		batchSlugs[org.Slug] = true
+	}
+	if err := s.repo.CreateMany(valid, actor); err != nil {
+		return nil, err
+	}
+	report.Created = len(valid)
+
+	events := make([]*audit.AuditLog, 0, len(valid))
+	for _, org := range valid {
+		events = append(events, &audit.AuditLog{
+			EntityType:  auditEntityType,
+			EntityID:    org.ID,
+			Action:      "IMPORT",
+			ToStatus:    string(StatusDraft),
+			PerformedBy: actor,
+		})
+	}
+	if err := s.auditRepo.LogAll(events); err != nil {
+		// No revertimos la importación por un fallo de auditoría
+		log.Printf("audit: could not record import: %v", err)
+	}
+
+	return report, nil
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
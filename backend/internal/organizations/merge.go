@@ -0,0 +1,134 @@
+package organizations
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Valores posibles de cada campo en MergeRequest.Fields.
+const (
+	mergeKeepTarget = "target"
+	mergeTakeSource = "source"
+)
+
+// MergeRequest fusiona SourceID en la organización de la URL (target).
+// Fields elige, por campo editable, qué valor sobrevive ("target" o
+// "source"). Sin elección se conserva el del target salvo que esté vacío.
+// Los campos multivaluados (tags, technology, ...) siempre se unen.
+type MergeRequest struct {
+	SourceID string            `json:"sourceId"`
+	Fields   map[string]string `json:"fields"`
+}
+
+// mergeRecords arma la organización resultante de fusionar source en target.
+func mergeRecords(target, source *Organization, choices map[string]string) (*Organization, error) {
+	editable := make(map[string]bool, len(editableFields))
+	for _, f := range editableFields {
+		editable[f.Name] = true
+	}
+	for field, choice := range choices {
+		if !editable[field] {
+			return nil, fmt.Errorf("unknown field: %s", field)
+		}
+		if choice != mergeKeepTarget && choice != mergeTakeSource {
+			return nil, fmt.Errorf("%s must be %q or %q", field, mergeKeepTarget, mergeTakeSource)
+		}
+	}
+
+	targetDoc, err := toDocument(target)
+	if err != nil {
+		return nil, err
+	}
+	sourceDoc, err := toDocument(source)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range editableFields {
+		if sourceValues, ok := multiValues(source, f.Name); ok {
+			targetValues, _ := multiValues(target, f.Name)
+			if union := unionValues(targetValues, sourceValues); len(union) > 0 {
+				targetDoc[f.Name] = union
+			}
+			continue
+		}
+
+		takeSource := choices[f.Name] == mergeTakeSource ||
+			(choices[f.Name] == "" && isBlankValue(targetDoc[f.Name]))
+		if !takeSource {
+			continue
+		}
+		if v, ok := sourceDoc[f.Name]; ok {
+			targetDoc[f.Name] = v
+		} else {
+			delete(targetDoc, f.Name)
+		}
+	}
+
+	data, err := json.Marshal(targetDoc)
+	if err != nil {
+		return nil, err
+	}
+	var merged Organization
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+	return &merged, nil
+}
+
+func toDocument(org *Organization) (map[string]any, error) {
+	data, err := json.Marshal(org)
+	if err != nil {
+		return nil, err
+	}
+	doc := make(map[string]any)
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func isBlankValue(v any) bool {
+	return v == nil || v == ""
+}
+
+// unionValues concatena sin repetir, respetando el orden (primero los de a).
+func unionValues(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	out := make([]string, 0, len(a)+len(b))
+	for _, v := range append(append([]string{}, a...), b...) {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// publicFinder son las búsquedas de findPublic.
+type publicFinder interface {
+	FindPublishedByID(id string) (*Organization, error)
+	FindPublishedBySlug(slug string) (*Organization, error)
+	ResolveAlias(id string) (string, error)
+}
+
+// findPublic busca una organización publicada por id o por slug. Un id (o
+// slug) absorbido por un merge devuelve en redirect el id vigente, solo si
+// ese está publicado: si no, el redirect lo delataría.
+func findPublic(repo publicFinder, id string) (*Organization, string, error) {
+	org, err := repo.FindPublishedByID(id)
+	if err != nil {
+		org, err = repo.FindPublishedBySlug(id)
+	}
+	if err == nil {
+		return org, "", nil
+	}
+	target, err := repo.ResolveAlias(id)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := repo.FindPublishedByID(target); err != nil {
+		return nil, "", err
+	}
+	return nil, target, nil
+}
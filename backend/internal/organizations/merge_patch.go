@@ -0,0 +1,66 @@
+package organizations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// readOnlyFields no pueden modificarse mediante PATCH.
+var readOnlyFields = []string{"id", "slug", "status", "version", "createdAt", "updatedAt", "relevance", "highlights", "distanceKm", "scheduleError"}
+
+// applyMergePatch aplica un JSON Merge Patch (RFC 7396) sobre target.
+// Un null en el patch elimina la clave; los objetos se fusionan recursivamente
+// y cualquier otro valor (incluidos arrays) reemplaza al existente.
+func applyMergePatch(target any, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = make(map[string]any)
+	}
+
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+			continue
+		}
+		targetObj[k] = applyMergePatch(targetObj[k], v)
+	}
+	return targetObj
+}
+
+// mergeOrganization devuelve una copia de org con el patch aplicado.
+func mergeOrganization(org *Organization, patch map[string]any) (*Organization, error) {
+	for _, f := range readOnlyFields {
+		if _, ok := patch[f]; ok {
+			return nil, fmt.Errorf("field %s is read-only", f)
+		}
+	}
+
+	current, err := json.Marshal(org)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(current, &doc); err != nil {
+		return nil, err
+	}
+
+	merged, err := json.Marshal(applyMergePatch(doc, patch))
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+
+	var result Organization
+	if err := decoder.Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,139 @@
+package organizations
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func decodeAny(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", s, err)
+	}
+	return v
+}
+
+// TestApplyMergePatchRFC7396 recorre los ejemplos del apéndice A de la RFC 7396.
+func TestApplyMergePatchRFC7396(t *testing.T) {
+	tests := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target+" + "+tt.patch, func(t *testing.T) {
+			got := applyMergePatch(decodeAny(t, tt.target), decodeAny(t, tt.patch))
+			if want := decodeAny(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func patchedOrganization(t *testing.T, patch string) (*Organization, error) {
+	t.Helper()
+	website := "https://example.org"
+	year := 2015
+	org := &Organization{
+		ID:          "org-1",
+		Name:        "Acme",
+		Country:     "AR",
+		Website:     &website,
+		YearFounded: &year,
+		Tags:        []string{"agro", "water"},
+		Status:      StatusDraft,
+		Version:     4,
+	}
+	var p map[string]any
+	if err := json.Unmarshal([]byte(patch), &p); err != nil {
+		t.Fatalf("invalid patch: %v", err)
+	}
+	return mergeOrganization(org, p)
+}
+
+func TestMergeOrganization(t *testing.T) {
+	t.Run("only sent fields change", func(t *testing.T) {
+		org, err := patchedOrganization(t, `{"name":"Acme SA"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if org.Name != "Acme SA" || org.Country != "AR" || org.Website == nil || *org.YearFounded != 2015 {
+			t.Errorf("unexpected result %+v", org)
+		}
+		if !reflect.DeepEqual(org.Tags, []string{"agro", "water"}) {
+			t.Errorf("tags = %v, want untouched", org.Tags)
+		}
+	})
+
+	t.Run("null deletes the value", func(t *testing.T) {
+		org, err := patchedOrganization(t, `{"website":null,"yearFounded":null}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if org.Website != nil || org.YearFounded != nil {
+			t.Errorf("website = %v, yearFounded = %v, want both nil", org.Website, org.YearFounded)
+		}
+		if org.Name != "Acme" {
+			t.Errorf("name = %q, want untouched", org.Name)
+		}
+	})
+
+	t.Run("arrays are replaced, not merged", func(t *testing.T) {
+		org, err := patchedOrganization(t, `{"tags":["energy"]}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(org.Tags, []string{"energy"}) {
+			t.Errorf("tags = %v, want [energy]", org.Tags)
+		}
+	})
+
+	t.Run("original is not modified", func(t *testing.T) {
+		website := "https://example.org"
+		org := &Organization{ID: "org-1", Name: "Acme", Website: &website}
+		if _, err := mergeOrganization(org, map[string]any{"website": nil, "name": "Other"}); err != nil {
+			t.Fatal(err)
+		}
+		if org.Website == nil || org.Name != "Acme" {
+			t.Errorf("mergeOrganization mutated its input: %+v", org)
+		}
+	})
+
+	for _, field := range readOnlyFields {
+		t.Run("read-only "+field, func(t *testing.T) {
+			_, err := patchedOrganization(t, `{"`+field+`":"x"}`)
+			if err == nil || !strings.Contains(err.Error(), "read-only") {
+				t.Errorf("err = %v, want read-only error", err)
+			}
+		})
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		if _, err := patchedOrganization(t, `{"nmae":"typo"}`); err == nil {
+			t.Error("unknown field was accepted")
+		}
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		if _, err := patchedOrganization(t, `{"yearFounded":"2015"}`); err == nil {
+			t.Error("string accepted for yearFounded")
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package organizations
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+// fakePublicRepo guarda organizaciones y alias en memoria, como quedan en la
+// base después de Repository.Merge.
+type fakePublicRepo struct {
+	orgs    map[string]*Organization
+	aliases map[string]string
+}
+
+func (f *fakePublicRepo) FindPublishedByID(id string) (*Organization, error) {
+	if org, ok := f.orgs[id]; ok && org.Status == StatusPublished {
+		return org, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakePublicRepo) FindPublishedBySlug(slug string) (*Organization, error) {
+	for _, org := range f.orgs {
+		if org.Slug == slug && org.Status == StatusPublished {
+			return org, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakePublicRepo) ResolveAlias(id string) (string, error) {
+	if target, ok := f.aliases[id]; ok {
+		return target, nil
+	}
+	return "", sql.ErrNoRows
+}
+
+// merge reproduce el efecto de Repository.Merge: borra source y deja su id y
+// su slug como alias de target.
+func (f *fakePublicRepo) merge(target, source string) {
+	src := f.orgs[source]
+	delete(f.orgs, source)
+	f.aliases[src.ID] = target
+	f.aliases[src.Slug] = target
+}
+
+func TestFindPublicRedirectsMergedPublishedSource(t *testing.T) {
+	repo := &fakePublicRepo{
+		orgs: map[string]*Organization{
+			"t": {ID: "t", Slug: "acme", Status: StatusPublished},
+			"s": {ID: "s", Slug: "acme-sa", Status: StatusPublished},
+			"d": {ID: "d", Slug: "draft-co", Status: StatusDraft},
+			"x": {ID: "x", Slug: "other", Status: StatusPublished},
+		},
+		aliases: map[string]string{},
+	}
+	repo.merge("t", "s") // publicado en publicado
+	repo.merge("d", "x") // publicado en borrador
+
+	tests := []struct {
+		id       string
+		wantOrg  string
+		redirect string
+		notFound bool
+	}{
+		{id: "t", wantOrg: "t"},
+		{id: "acme", wantOrg: "t"},
+		{id: "s", redirect: "t"},
+		{id: "acme-sa", redirect: "t"},
+		// El destino no es público: nada que delate que existe
+		{id: "x", notFound: true},
+		{id: "other", notFound: true},
+		{id: "d", notFound: true},
+		{id: "missing", notFound: true},
+	}
+	for _, tt := range tests {
+		org, redirect, err := findPublic(repo, tt.id)
+		switch {
+		case tt.notFound:
+			if err == nil {
+				t.Errorf("%s: got org=%v redirect=%q, want not found", tt.id, org, redirect)
+			}
+		case err != nil:
+			t.Errorf("%s: unexpected error %v", tt.id, err)
+		case redirect != tt.redirect:
+			t.Errorf("%s: redirect = %q, want %q", tt.id, redirect, tt.redirect)
+		case tt.wantOrg != "" && (org == nil || org.ID != tt.wantOrg):
+			t.Errorf("%s: org = %v, want %s", tt.id, org, tt.wantOrg)
+		}
+	}
+}
+
+func TestMergeRecords(t *testing.T) {
+	year := 2015
+	zero := 0.0
+	target := &Organization{
+		ID:               "t",
+		Slug:             "acme",
+		Version:          4,
+		Status:           StatusDraft,
+		Name:             "Acme",
+		OrganizationType: "STARTUP",
+		Country:          "Argentina",
+		City:             "",
+		Website:          strPtr("https://acme.com"),
+		Notes:            nil,
+		Description:      strPtr("target description"),
+		Lat:              &zero,
+		Tags:             []string{"agro", "iot"},
+		Technology:       nil,
+	}
+	source := &Organization{
+		ID:               "s",
+		Slug:             "acme-sa",
+		Version:          9,
+		Status:           StatusArchived,
+		Name:             "Acme S.A.",
+		OrganizationType: "COMPANY",
+		Country:          "Uruguay",
+		City:             "Rosario",
+		Website:          strPtr("https://acme.com.ar"),
+		Notes:            strPtr("source notes"),
+		Description:      nil,
+		YearFounded:      &year,
+		Lat:              nil,
+		Tags:             []string{"iot", "drones"},
+		Technology:       []string{"ai"},
+	}
+
+	merged, err := mergeRecords(target, source, map[string]string{
+		"name":        mergeTakeSource,
+		"country":     mergeKeepTarget,
+		"description": mergeTakeSource, // nil en el origen: se borra
+		"tags":        mergeKeepTarget, // los multivaluados se unen igual
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checks := []struct {
+		field     string
+		got, want any
+	}{
+		// Identidad y ciclo de vida son siempre del destino
+		{"id", merged.ID, "t"},
+		{"slug", merged.Slug, "acme"},
+		{"version", merged.Version, 4},
+		{"status", merged.Status, StatusDraft},
+		// Elección explícita
+		{"name", merged.Name, "Acme S.A."},
+		{"country", merged.Country, "Argentina"},
+		{"description", merged.Description == nil, true},
+		// Sin elección gana el destino, salvo que esté vacío o sea nil
+		{"organizationType", merged.OrganizationType, "STARTUP"},
+		{"website", *merged.Website, "https://acme.com"},
+		{"city", merged.City, "Rosario"},
+		{"notes", *merged.Notes, "source notes"},
+		{"yearFounded", *merged.YearFounded, 2015},
+		// Un 0 no es vacío: lat 0 es una coordenada válida
+		{"lat", *merged.Lat, 0.0},
+		// Unión en orden, sin repetidos
+		{"tags", merged.Tags, []string{"agro", "iot", "drones"}},
+		{"technology", merged.Technology, []string{"ai"}},
+		{"impactArea", merged.ImpactArea == nil, true},
+	}
+	for _, c := range checks {
+		if !reflect.DeepEqual(c.got, c.want) {
+			t.Errorf("%s = %v, want %v", c.field, c.got, c.want)
+		}
+	}
+
+	// Las entradas no se modifican
+	if target.Name != "Acme" || !reflect.DeepEqual(target.Tags, []string{"agro", "iot"}) || target.Description == nil {
+		t.Errorf("target was modified: %+v", target)
+	}
+	if !reflect.DeepEqual(source.Tags, []string{"iot", "drones"}) {
+		t.Errorf("source was modified: %+v", source)
+	}
+}
+
+func TestMergeRecordsKeepTargetNil(t *testing.T) {
+	target := &Organization{ID: "t", Name: "Acme"}
+	source := &Organization{ID: "s", Name: "Acme", Website: strPtr("https://acme.com")}
+
+	merged, err := mergeRecords(target, source, map[string]string{"website": mergeKeepTarget})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Website != nil {
+		t.Errorf("website = %q, want nil (target chosen explicitly)", *merged.Website)
+	}
+
+	merged, err = mergeRecords(target, source, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Website == nil || *merged.Website != "https://acme.com" {
+		t.Errorf("website = %v, want the source value filling the blank", merged.Website)
+	}
+}
+
+func TestMergeRecordsRejectsBadChoices(t *testing.T) {
+	tests := map[string]map[string]string{
+		"unknown field":     {"nope": mergeTakeSource},
+		"not editable":      {"status": mergeTakeSource},
+		"invalid choice":    {"name": "both"},
+		"empty choice text": {"name": ""},
+	}
+	for name, choices := range tests {
+		if _, err := mergeRecords(&Organization{}, &Organization{}, choices); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package organizations
+
+import "strings"
+
+// multiValueFilter describe un campo multivaluado guardado como arreglo JSON.
+// Param es el parámetro de query (?technology=AI,IoT) y Param+"Match" elige
+// la semántica: "any" (por defecto, alguno de los valores) o "all" (todos).
+// La forma negada (?not_technology=AI) excluye a quien tenga cualquiera.
+type multiValueFilter struct {
+	Param  string
+	Column string
+}
+
+var multiValueFilters = []multiValueFilter{
+	{Param: "technology", Column: "technology_json"},
+	{Param: "impactArea", Column: "impact_area_json"},
+	{Param: "badge", Column: "badge_json"},
+	{Param: "tag", Column: "tags_json"},
+}
+
+// multiValueCondition arma la condición SQL para un filtro multivaluado.
+// Devuelve "" si el parámetro no viene.
+func multiValueCondition(f multiValueFilter, params map[string]string) (string, []interface{}) {
+	var query string
+	args := make([]interface{}, 0)
+
+	if values := filterValues(params[f.Param]); len(values) > 0 {
+		joiner := " OR "
+		if strings.EqualFold(params[f.Param+"Match"], "all") {
+			joiner = " AND "
+		}
+		query += " AND (" + jsonContainsAny(f.Column, len(values), joiner) + ")"
+		args = appendStrings(args, values)
+	}
+
+	if values := filterValues(params[negatedPrefix+f.Param]); len(values) > 0 {
+		query += " AND NOT (" + jsonContainsAny(f.Column, len(values), " OR ") + ")"
+		args = appendStrings(args, values)
+	}
+	return query, args
+}
+
+// jsonContainsAny repite JSON_CONTAINS por cada valor. COALESCE evita que un
+// arreglo nulo deje la condición en NULL (y la negación sin filas).
+func jsonContainsAny(column string, n int, joiner string) string {
+	conds := make([]string, n)
+	for i := range conds {
+		conds[i] = "COALESCE(JSON_CONTAINS(" + column + ", JSON_QUOTE(?)), 0)"
+	}
+	return strings.Join(conds, joiner)
+}
+
+// jsonValuesTable expande un arreglo JSON en filas (una por valor) con JSON_TABLE.
+// Los valores nulos o con JSON inválido se tratan como arreglo vacío.
+func jsonValuesTable(column string) string {
+	return "JSON_TABLE(IF(JSON_VALID(" + column + "), " + column + ", '[]'), '$[*]' COLUMNS (value VARCHAR(255) PATH '$')) AS jt"
+}
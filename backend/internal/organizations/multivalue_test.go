@@ -0,0 +1,127 @@
+package organizations
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMultiValueCondition(t *testing.T) {
+	tech := multiValueFilter{Param: "technology", Column: "technology_json"}
+	contains := "COALESCE(JSON_CONTAINS(technology_json, JSON_QUOTE(?)), 0)"
+
+	tests := []struct {
+		name   string
+		params map[string]string
+		query  string
+		args   []interface{}
+	}{
+		{
+			name:   "absent",
+			params: map[string]string{"tag": "agro"},
+			query:  "",
+			args:   []interface{}{},
+		},
+		{
+			name:   "single value",
+			params: map[string]string{"technology": "AI"},
+			query:  " AND (" + contains + ")",
+			args:   []interface{}{"AI"},
+		},
+		{
+			name:   "any by default",
+			params: map[string]string{"technology": "AI, IoT,,"},
+			query:  " AND (" + contains + " OR " + contains + ")",
+			args:   []interface{}{"AI", "IoT"},
+		},
+		{
+			name:   "all",
+			params: map[string]string{"technology": "AI,IoT", "technologyMatch": "ALL"},
+			query:  " AND (" + contains + " AND " + contains + ")",
+			args:   []interface{}{"AI", "IoT"},
+		},
+		{
+			name:   "negated is always any",
+			params: map[string]string{"not_technology": "Blockchain,VR", "technologyMatch": "all"},
+			query:  " AND NOT (" + contains + " OR " + contains + ")",
+			args:   []interface{}{"Blockchain", "VR"},
+		},
+		{
+			name:   "included and excluded",
+			params: map[string]string{"technology": "AI", "not_technology": "VR"},
+			query:  " AND (" + contains + ") AND NOT (" + contains + ")",
+			args:   []interface{}{"AI", "VR"},
+		},
+		{
+			name:   "only separators",
+			params: map[string]string{"technology": " , "},
+			query:  "",
+			args:   []interface{}{},
+		},
+	}
+	for _, tt := range tests {
+		query, args := multiValueCondition(tech, tt.params)
+		if query != tt.query {
+			t.Errorf("%s: query = %q, want %q", tt.name, query, tt.query)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: args = %v, want %v", tt.name, args, tt.args)
+		}
+	}
+}
+
+func TestWithoutFilter(t *testing.T) {
+	params := map[string]string{
+		"status":          "PUBLISHED",
+		"country":         "Argentina",
+		"technology":      "AI",
+		"not_technology":  "VR",
+		"technologyMatch": "all",
+		"tag":             "agro",
+	}
+	got := withoutFilter(params, "technology")
+	want := map[string]string{"status": "PUBLISHED", "country": "Argentina", "tag": "agro"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("withoutFilter = %v, want %v", got, want)
+	}
+	if len(params) != 6 {
+		t.Errorf("params was modified: %v", params)
+	}
+}
+
+// Cada facet cuenta con todos los filtros menos el suyo: elegir un país no
+// esconde a los demás países, pero sí acota las tecnologías.
+func TestFacetWhereIsDisjunctive(t *testing.T) {
+	r := &Repository{}
+	params := map[string]string{"status": "PUBLISHED", "country": "Argentina", "technology": "AI"}
+
+	countryWhere, countryArgs := r.buildWhereClause(withoutFilter(params, "country"))
+	if strings.Contains(countryWhere, "country") || containsArg(countryArgs, "Argentina") {
+		t.Errorf("country facet is filtered by country: %q %v", countryWhere, countryArgs)
+	}
+	if !strings.Contains(countryWhere, "technology_json") || !containsArg(countryArgs, "AI") {
+		t.Errorf("country facet ignores the technology filter: %q %v", countryWhere, countryArgs)
+	}
+
+	techWhere, techArgs := r.buildWhereClause(withoutFilter(params, "technology"))
+	if strings.Contains(techWhere, "technology_json") || containsArg(techArgs, "AI") {
+		t.Errorf("technology facet is filtered by technology: %q %v", techWhere, techArgs)
+	}
+	if !strings.Contains(techWhere, "country") || !containsArg(techArgs, "Argentina") {
+		t.Errorf("technology facet ignores the country filter: %q %v", techWhere, techArgs)
+	}
+	for _, args := range [][]interface{}{countryArgs, techArgs} {
+		if !containsArg(args, "PUBLISHED") {
+			t.Errorf("facet lost the status filter: %v", args)
+		}
+	}
+}
+
+func containsArg(args []interface{}, want string) bool {
+	for _, a := range args {
+		if a == want {
+			return true
+		}
+	}
+	return false
+}
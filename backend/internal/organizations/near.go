@@ -0,0 +1,107 @@
+package organizations
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusKm es el radio medio terrestre usado en la fórmula de haversine.
+const earthRadiusKm = 6371.0
+
+// maxRadiusKm limita ?radiusKm= (media circunferencia terrestre).
+const maxRadiusKm = 20000.0
+
+// sortDistance es el valor de ?sort= que ordena por cercanía a ?near=.
+const sortDistance = "distance"
+
+// distanceExpr calcula la distancia por círculo máximo (haversine) entre
+// (lat, lng) de la fila y el punto dado. Parámetros: lat, lat, lng.
+const distanceExpr = "(2 * 6371 * ASIN(SQRT(" +
+	"POWER(SIN(RADIANS(lat - ?) / 2), 2) + " +
+	"COS(RADIANS(?)) * COS(RADIANS(lat)) * POWER(SIN(RADIANS(lng - ?) / 2), 2))))"
+
+// nearQuery es un punto de búsqueda por proximidad; RadiusKm 0 = sin límite.
+type nearQuery struct {
+	Lat      float64
+	Lng      float64
+	RadiusKm float64
+}
+
+// parseNear lee ?near=lat,lng y ?radiusKm=. Devuelve nil si no viene near.
+func parseNear(params map[string]string) (*nearQuery, error) {
+	near := strings.TrimSpace(params["near"])
+	if near == "" {
+		if params["radiusKm"] != "" || params["sort"] == sortDistance {
+			return nil, fmt.Errorf("near=lat,lng is required for radiusKm and sort=distance")
+		}
+		return nil, nil
+	}
+
+	parts := strings.Split(near, ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("near must be lat,lng")
+	}
+	lat, errLat := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lng, errLng := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if errLat != nil || errLng != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, fmt.Errorf("near must be a valid lat,lng")
+	}
+
+	n := &nearQuery{Lat: lat, Lng: lng}
+	if s := params["radiusKm"]; s != "" {
+		radius, err := strconv.ParseFloat(s, 64)
+		if err != nil || radius <= 0 || radius > maxRadiusKm {
+			return nil, fmt.Errorf("radiusKm must be between 0 and %.0f", maxRadiusKm)
+		}
+		n.RadiusKm = radius
+	}
+	return n, nil
+}
+
+// condition filtra por radio. Antes del cálculo exacto agrega un recuadro
+// lat/lng que lo contiene, para descartar filas sin trigonometría.
+func (n *nearQuery) condition() (string, []interface{}) {
+	query := " AND lat IS NOT NULL AND lng IS NOT NULL"
+	args := make([]interface{}, 0)
+	if n.RadiusKm == 0 {
+		return query, args
+	}
+
+	query += " AND lat BETWEEN ? AND ?"
+	dLat := n.RadiusKm / (earthRadiusKm * math.Pi / 180)
+	args = append(args, n.Lat-dLat, n.Lat+dLat)
+
+	// Si el círculo incluye un polo (o cruza el antimeridiano) el recuadro en lng no sirve
+	if dLng, ok := n.lngHalfWidth(); ok && n.Lng-dLng >= -180 && n.Lng+dLng <= 180 {
+		query += " AND lng BETWEEN ? AND ?"
+		args = append(args, n.Lng-dLng, n.Lng+dLng)
+	}
+
+	query += " AND " + distanceExpr + " <= ?"
+	args = append(args, n.distanceArgs()...)
+	args = append(args, n.RadiusKm)
+	return query, args
+}
+
+// lngHalfWidth es la mitad del ancho en grados de longitud del círculo:
+// asin(sin(r/R) / cos(lat)). No es dLat/cos(lat), que se queda corto en
+// latitudes altas. ok es false si el círculo incluye un polo, donde abarca
+// todas las longitudes.
+func (n *nearQuery) lngHalfWidth() (float64, bool) {
+	angular := n.RadiusKm / earthRadiusKm
+	cosLat := math.Cos(n.Lat * math.Pi / 180)
+	if angular >= math.Pi/2 || cosLat < 1e-9 {
+		return 0, false
+	}
+	ratio := math.Sin(angular) / cosLat
+	if ratio >= 1 {
+		return 0, false
+	}
+	return math.Asin(ratio) * 180 / math.Pi, true
+}
+
+func (n *nearQuery) distanceArgs() []interface{} {
+	return []interface{}{n.Lat, n.Lat, n.Lng}
+}
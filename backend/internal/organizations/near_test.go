@@ -0,0 +1,82 @@
+package organizations
+
+import (
+	"math"
+	"testing"
+)
+
+// haversineKm es la misma fórmula que distanceExpr.
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	rad := math.Pi / 180
+	a := math.Pow(math.Sin((lat2-lat1)*rad/2), 2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Pow(math.Sin((lng2-lng1)*rad/2), 2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+func TestNearLngHalfWidthContainsCircle(t *testing.T) {
+	tests := []nearQuery{
+		{Lat: 0, Lng: 0, RadiusKm: 100},
+		{Lat: -34.6, Lng: -58.4, RadiusKm: 50},
+		{Lat: 60, Lng: 10, RadiusKm: 1000},
+		{Lat: -75, Lng: 0, RadiusKm: 1200},
+		{Lat: 80, Lng: 0, RadiusKm: 1000},
+	}
+	for _, n := range tests {
+		dLng, ok := n.lngHalfWidth()
+		if !ok {
+			t.Errorf("%+v: expected a longitude box", n)
+			continue
+		}
+		// El meridiano lng+dLng es tangente al círculo: su punto más cercano
+		// al centro está exactamente a r.
+		widest := math.Inf(1)
+		for lat := -90.0; lat <= 90; lat += 0.05 {
+			if d := haversineKm(n.Lat, n.Lng, lat, n.Lng+dLng); d < widest {
+				widest = d
+			}
+		}
+		if widest < n.RadiusKm*(1-1e-4) {
+			t.Errorf("%+v: dLng=%.4f leaves points within %.3f km outside the box", n, dLng, widest)
+		}
+		if widest > n.RadiusKm*(1+1e-3) {
+			t.Errorf("%+v: dLng=%.4f is wider than needed (closest %.3f km)", n, dLng, widest)
+		}
+	}
+}
+
+func TestNearLngHalfWidthHighLatitude(t *testing.T) {
+	// A 80° y 1000 km, dLat/cos(lat) daría ~51.8°; el círculo abarca ~66°
+	n := nearQuery{Lat: 80, Lng: 0, RadiusKm: 1000}
+	dLng, _ := n.lngHalfWidth()
+	naive := n.RadiusKm / (earthRadiusKm * math.Pi / 180) / math.Cos(n.Lat*math.Pi/180)
+	if dLng <= naive {
+		t.Errorf("dLng = %.2f, want more than %.2f", dLng, naive)
+	}
+}
+
+func TestNearLngHalfWidthPoles(t *testing.T) {
+	tests := []nearQuery{
+		{Lat: 89, Lng: 0, RadiusKm: 200},  // el círculo incluye el polo norte
+		{Lat: -85, Lng: 0, RadiusKm: 600}, // y el sur
+		{Lat: 90, Lng: 0, RadiusKm: 1},
+		{Lat: 0, Lng: 0, RadiusKm: 15000}, // más de un cuarto de circunferencia
+	}
+	for _, n := range tests {
+		if dLng, ok := n.lngHalfWidth(); ok {
+			t.Errorf("%+v: got dLng=%.2f, want no longitude box", n, dLng)
+		}
+	}
+}
+
+func TestNearConditionSkipsLngBoxAcrossAntimeridian(t *testing.T) {
+	n := nearQuery{Lat: 0, Lng: 179.5, RadiusKm: 200}
+	_, args := n.condition()
+	// lat BETWEEN (2) + distancia (lat, lat, lng, radio)
+	if len(args) != 6 {
+		t.Errorf("args = %v, want no lng BETWEEN", args)
+	}
+	n = nearQuery{Lat: 0, Lng: 0, RadiusKm: 200}
+	if _, args := n.condition(); len(args) != 8 {
+		t.Errorf("args = %v, want a lng BETWEEN", args)
+	}
+}
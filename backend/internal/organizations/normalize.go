@@ -1,10 +1,35 @@
 package organizations
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
+// ValidationError indica que los datos enviados por el cliente son inválidos
+// (a diferencia de un error de infraestructura). En HTTP se traduce a 400.
+type ValidationError struct {
+	Err error
+}
+
+func (e ValidationError) Error() string { return e.Err.Error() }
+func (e ValidationError) Unwrap() error { return e.Err }
+
+// invalid marca err como ValidationError.
+func invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+	return ValidationError{Err: err}
+}
+
+// isValidationError informa si err (o algún error envuelto) es de validación.
+func isValidationError(err error) bool {
+	var ve ValidationError
+	return errors.As(err, &ve)
+}
+
 // Normalize limpia y valida los datos de una organización antes de persistirlos.
 func Normalize(org *Organization) error {
 	// 1. Trim en strings obligatorias
@@ -17,9 +42,9 @@ func Normalize(org *Organization) error {
 	org.Region = strings.TrimSpace(org.Region)
 	org.City = strings.TrimSpace(org.City)
 
-	// 2. Validar campos ID y Nombre (mínimo para DRAFT)
-	if org.ID == "" {
-		return fmt.Errorf("id is required")
+	// 2. Validar nombre (mínimo para DRAFT); sin id, Create genera un UUIDv7
+	if len(org.ID) > 36 {
+		return fmt.Errorf("id must be at most 36 characters")
 	}
 	if org.Name == "" {
 		return fmt.Errorf("name is required")
@@ -50,9 +75,24 @@ func Normalize(org *Organization) error {
 		}
 	}
 
+	// 6. Publicación programada: en UTC y al segundo (como se guarda)
+	org.PublishAt = normalizeTime(org.PublishAt)
+	org.UnpublishAt = normalizeTime(org.UnpublishAt)
+	if org.PublishAt != nil && org.UnpublishAt != nil && !org.UnpublishAt.After(*org.PublishAt) {
+		return fmt.Errorf("unpublishAt must be after publishAt")
+	}
+
 	return nil
 }
 
+func normalizeTime(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	v := t.UTC().Truncate(time.Second)
+	return &v
+}
+
 func normalizeOptional(s *string) *string {
 	if s == nil {
 		return nil
@@ -0,0 +1,129 @@
+package organizations
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// pageLimits es el tamaño de página por defecto y el máximo que acepta un listado.
+type pageLimits struct {
+	Default int
+	Max     int
+}
+
+var (
+	publicPageLimits = pageLimits{Default: 100, Max: 500}
+	adminPageLimits  = pageLimits{Default: 500, Max: 5000}
+)
+
+// PageRequest pide una página: Cursor es el nextCursor de la respuesta
+// anterior ("" = primera página). Offset mantiene el ?offset= de los clientes
+// anteriores al cursor; solo aplica a la primera página.
+type PageRequest struct {
+	Limit  int
+	Cursor string
+	Offset int
+}
+
+// PageResult es una página de organizaciones más el total de coincidencias.
+type PageResult struct {
+	Items      []Organization
+	Total      int
+	NextCursor string
+}
+
+// pageCursor es el contenido del cursor opaco: la última fila vista
+// (updated_at, id) o, para órdenes sin keyset, un offset.
+type pageCursor struct {
+	UpdatedAt *time.Time `json:"u,omitempty"`
+	ID        string     `json:"i,omitempty"`
+	Offset    int        `json:"o,omitempty"`
+}
+
+// pageQuery es el recorte que se aplica a la consulta SQL.
+type pageQuery struct {
+	limit  int
+	offset int
+	after  *pageCursor
+}
+
+func encodeCursor(c *pageCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*pageCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Offset < 0 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &c, nil
+}
+
+// parsePageRequest lee ?limit=, ?cursor= y ?offset= aplicando los límites del listado.
+func parsePageRequest(params map[string]string, limits pageLimits) (PageRequest, error) {
+	req := PageRequest{Limit: limits.Default, Cursor: params["cursor"]}
+	if s := params["limit"]; s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 {
+			return req, invalid(fmt.Errorf("limit must be a positive integer"))
+		}
+		req.Limit = min(limit, limits.Max)
+	}
+	if s := params["offset"]; s != "" {
+		offset, err := strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			return req, invalid(fmt.Errorf("offset must be a non-negative integer"))
+		}
+		if req.Cursor != "" {
+			return req, invalid(fmt.Errorf("offset cannot be combined with cursor"))
+		}
+		req.Offset = offset
+	}
+	return req, nil
+}
+
+// keysetCondition filtra las filas posteriores a after en el orden
+// updated_at DESC, id DESC.
+func keysetCondition(after *pageCursor) (string, []interface{}) {
+	if after == nil || after.UpdatedAt == nil {
+		return "", nil
+	}
+	return " AND (updated_at < ? OR (updated_at = ? AND id < ?))",
+		[]interface{}{*after.UpdatedAt, *after.UpdatedAt, after.ID}
+}
+
+// writePageHeaders agrega X-Total-Count, X-Next-Cursor y los Link (RFC 8288)
+// first/next, que repiten la consulta original cambiando solo el cursor.
+func writePageHeaders(w http.ResponseWriter, r *http.Request, page *PageResult) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+
+	link := func(cursor, rel string) string {
+		u := *r.URL
+		q := u.Query()
+		q.Del("cursor")
+		q.Del("offset") // el cursor ya incluye la posición
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+		u.RawQuery = q.Encode()
+		return fmt.Sprintf("<%s>; rel=%q", u.RequestURI(), rel)
+	}
+
+	w.Header().Add("Link", link("", "first"))
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+		w.Header().Add("Link", link(page.NextCursor, "next"))
+	}
+}
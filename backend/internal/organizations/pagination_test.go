@@ -0,0 +1,128 @@
+package organizations
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	updated := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	for _, c := range []*pageCursor{
+		{UpdatedAt: &updated, ID: "0190f3a2-7c1e-7b3a-9d2f-1a2b3c4d5e6f"},
+		{Offset: 500},
+	} {
+		s := encodeCursor(c)
+		if strings.ContainsAny(s, "+/=") {
+			t.Errorf("cursor %q is not URL-safe", s)
+		}
+		got, err := decodeCursor(s)
+		if err != nil {
+			t.Fatalf("decodeCursor(%q): %v", s, err)
+		}
+		if !reflect.DeepEqual(got, c) {
+			t.Errorf("round trip = %+v, want %+v", got, c)
+		}
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	if c, err := decodeCursor(""); c != nil || err != nil {
+		t.Errorf("empty cursor = %+v, %v; want nil, nil", c, err)
+	}
+	for _, s := range []string{"%%%", "bm90IGpzb24", encodeCursor(&pageCursor{Offset: -1})} {
+		if _, err := decodeCursor(s); err == nil {
+			t.Errorf("decodeCursor(%q) accepted an invalid cursor", s)
+		}
+	}
+}
+
+func TestParsePageRequest(t *testing.T) {
+	limits := pageLimits{Default: 100, Max: 500}
+	tests := []struct {
+		name    string
+		params  map[string]string
+		want    PageRequest
+		wantErr bool
+	}{
+		{"defaults", map[string]string{}, PageRequest{Limit: 100}, false},
+		{"limit", map[string]string{"limit": "20"}, PageRequest{Limit: 20}, false},
+		{"limit is capped", map[string]string{"limit": "10000"}, PageRequest{Limit: 500}, false},
+		{"cursor", map[string]string{"cursor": "abc"}, PageRequest{Limit: 100, Cursor: "abc"}, false},
+		{"legacy offset", map[string]string{"offset": "200", "limit": "50"}, PageRequest{Limit: 50, Offset: 200}, false},
+		{"zero limit", map[string]string{"limit": "0"}, PageRequest{}, true},
+		{"bad limit", map[string]string{"limit": "ten"}, PageRequest{}, true},
+		{"negative offset", map[string]string{"offset": "-1"}, PageRequest{}, true},
+		{"offset with cursor", map[string]string{"offset": "10", "cursor": "abc"}, PageRequest{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePageRequest(tt.params, limits)
+			if tt.wantErr {
+				if err == nil || !isValidationError(err) {
+					t.Fatalf("err = %v, want validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	if sql, args := keysetCondition(nil); sql != "" || args != nil {
+		t.Errorf("nil cursor = %q %v, want no condition", sql, args)
+	}
+	if sql, _ := keysetCondition(&pageCursor{Offset: 100}); sql != "" {
+		t.Errorf("offset cursor = %q, want no condition", sql)
+	}
+
+	updated := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	sql, args := keysetCondition(&pageCursor{UpdatedAt: &updated, ID: "b"})
+	if sql != " AND (updated_at < ? OR (updated_at = ? AND id < ?))" {
+		t.Errorf("sql = %q", sql)
+	}
+	if want := []interface{}{updated, updated, "b"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestWritePageHeaders(t *testing.T) {
+	r := httptest.NewRequest("GET", "/organizations?country=AR&limit=2&offset=4&cursor=old", nil)
+	w := httptest.NewRecorder()
+	writePageHeaders(w, r, &PageResult{Total: 7, NextCursor: "next123"})
+
+	if got := w.Header().Get("X-Total-Count"); got != "7" {
+		t.Errorf("X-Total-Count = %q", got)
+	}
+	if got := w.Header().Get("X-Next-Cursor"); got != "next123" {
+		t.Errorf("X-Next-Cursor = %q", got)
+	}
+	want := []string{
+		`</organizations?country=AR&limit=2>; rel="first"`,
+		`</organizations?country=AR&cursor=next123&limit=2>; rel="next"`,
+	}
+	if got := w.Header().Values("Link"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Link = %q, want %q", got, want)
+	}
+}
+
+func TestWritePageHeadersLastPage(t *testing.T) {
+	r := httptest.NewRequest("GET", "/organizations?cursor=abc", nil)
+	w := httptest.NewRecorder()
+	writePageHeaders(w, r, &PageResult{Total: 3})
+
+	if got := w.Header().Get("X-Next-Cursor"); got != "" {
+		t.Errorf("X-Next-Cursor = %q on the last page", got)
+	}
+	if got := w.Header().Values("Link"); len(got) != 1 || !strings.HasSuffix(got[0], `rel="first"`) {
+		t.Errorf("Link = %q, want only first", got)
+	}
+}
@@ -0,0 +1,252 @@
+package organizations
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// maxPolygonVertices limita el tamaño de la geometría que acepta la búsqueda por área.
+const maxPolygonVertices = 50000
+
+// AreaSearchResponse es el resultado de buscar organizaciones dentro de un área.
+type AreaSearchResponse struct {
+	Total      int                `json:"total"`
+	Items      []Organization     `json:"items"`
+	Aggregates AggregatesResponse `json:"aggregates"`
+}
+
+// geoJSONGeometry cubre Polygon, MultiPolygon y un Feature que los envuelva.
+type geoJSONGeometry struct {
+	Type        string           `json:"type"`
+	Coordinates json.RawMessage  `json:"coordinates"`
+	Geometry    *geoJSONGeometry `json:"geometry"`
+}
+
+// ring es una lista de posiciones [lng, lat] (orden RFC 7946).
+type ring [][2]float64
+
+// polygon es un anillo exterior seguido de sus huecos.
+type polygon []ring
+
+// area es un MultiPolygon ya validado.
+type area []polygon
+
+// parseArea valida un GeoJSON Polygon o MultiPolygon (o un Feature con uno).
+func parseArea(data []byte) (area, error) {
+	var g geoJSONGeometry
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+	if g.Type == "Feature" {
+		if g.Geometry == nil {
+			return nil, fmt.Errorf("feature has no geometry")
+		}
+		g = *g.Geometry
+	}
+
+	var a area
+	switch g.Type {
+	case "Polygon":
+		var p polygon
+		if err := json.Unmarshal(g.Coordinates, &p); err != nil {
+			return nil, fmt.Errorf("invalid Polygon coordinates")
+		}
+		a = area{p}
+	case "MultiPolygon":
+		if err := json.Unmarshal(g.Coordinates, &a); err != nil {
+			return nil, fmt.Errorf("invalid MultiPolygon coordinates")
+		}
+	default:
+		return nil, fmt.Errorf("geometry must be a Polygon or MultiPolygon, got %q", g.Type)
+	}
+
+	if len(a) == 0 {
+		return nil, fmt.Errorf("geometry has no polygons")
+	}
+	vertices := 0
+	for _, p := range a {
+		if len(p) == 0 {
+			return nil, fmt.Errorf("polygon has no rings")
+		}
+		for _, r := range p {
+			if len(r) < 4 || r[0] != r[len(r)-1] {
+				return nil, fmt.Errorf("each ring must be closed and have at least 4 positions")
+			}
+			for _, pos := range r {
+				if pos[0] < -180 || pos[0] > 180 || pos[1] < -90 || pos[1] > 90 {
+					return nil, fmt.Errorf("position out of range: %v", pos)
+				}
+			}
+			if r.area() == 0 {
+				return nil, fmt.Errorf("ring has zero area")
+			}
+			vertices += len(r)
+		}
+	}
+	if vertices > maxPolygonVertices {
+		return nil, fmt.Errorf("geometry has more than %d vertices", maxPolygonVertices)
+	}
+	return a, nil
+}
+
+// bbox devuelve el recuadro que contiene el área en el formato del
+// parámetro ?bbox= (minLat,minLng,maxLat,maxLng).
+func (a area) bbox() string {
+	minLat, minLng := math.Inf(1), math.Inf(1)
+	maxLat, maxLng := math.Inf(-1), math.Inf(-1)
+	for _, p := range a {
+		for _, pos := range p[0] {
+			minLng, maxLng = math.Min(minLng, pos[0]), math.Max(maxLng, pos[0])
+			minLat, maxLat = math.Min(minLat, pos[1]), math.Max(maxLat, pos[1])
+		}
+	}
+	return fmt.Sprintf("%g,%g,%g,%g", minLat, minLng, maxLat, maxLng)
+}
+
+// contains informa si el punto cae dentro de algún polígono (y fuera de sus
+// huecos). El borde, también el de un hueco, cuenta como parte del polígono.
+func (a area) contains(lat, lng float64) bool {
+	for _, p := range a {
+		if !p[0].onBoundary(lat, lng) && !p[0].contains(lat, lng) {
+			continue
+		}
+		inHole := false
+		for _, hole := range p[1:] {
+			if hole.contains(lat, lng) && !hole.onBoundary(lat, lng) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
+
+// contains aplica ray casting (regla par-impar) sobre el anillo.
+func (r ring) contains(lat, lng float64) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		xi, yi := r[i][0], r[i][1]
+		xj, yj := r[j][0], r[j][1]
+		if (yi > lat) != (yj > lat) && lng < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// onBoundary informa si el punto cae sobre algún lado del anillo.
+func (r ring) onBoundary(lat, lng float64) bool {
+	const eps = 1e-12
+	for i := 1; i < len(r); i++ {
+		x1, y1 := r[i-1][0], r[i-1][1]
+		x2, y2 := r[i][0], r[i][1]
+		cross := (x2-x1)*(lat-y1) - (y2-y1)*(lng-x1)
+		if math.Abs(cross) > eps {
+			continue
+		}
+		if lng >= math.Min(x1, x2)-eps && lng <= math.Max(x1, x2)+eps &&
+			lat >= math.Min(y1, y2)-eps && lat <= math.Max(y1, y2)+eps {
+			return true
+		}
+	}
+	return false
+}
+
+// area es el área con signo del anillo (fórmula del shoelace), en grados².
+func (r ring) area() float64 {
+	sum := 0.0
+	for i := 1; i < len(r); i++ {
+		sum += r[i-1][0]*r[i][1] - r[i][0]*r[i-1][1]
+	}
+	return sum / 2
+}
+
+// aggregateOrganizations calcula en memoria los mismos conteos que GetAggregates
+// (sin faceting disyuntivo: el área ya es el único recorte).
+func aggregateOrganizations(orgs []Organization, yearBucket int) AggregatesResponse {
+	type counter map[string]int
+	counts := make([]counter, 12)
+	for i := range counts {
+		counts[i] = counter{}
+	}
+	add := func(c counter, v string) {
+		if v != "" {
+			c[v]++
+		}
+	}
+	addAll := func(c counter, values []string) {
+		seen := make(map[string]bool, len(values))
+		for _, v := range values {
+			if !seen[v] {
+				seen[v] = true
+				add(c, v)
+			}
+		}
+	}
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+
+	years := make(map[int]int)
+	for _, org := range orgs {
+		add(counts[0], org.Country)
+		add(counts[1], org.SectorPrimary)
+		add(counts[2], deref(org.SectorSecondary))
+		add(counts[3], org.OrganizationType)
+		add(counts[4], deref(org.Stage))
+		add(counts[5], org.OutcomeStatus)
+		addAll(counts[6], org.Technology)
+		addAll(counts[7], org.ImpactArea)
+		addAll(counts[8], org.Badge)
+		addAll(counts[9], org.Tags)
+		add(counts[10], org.Region)
+		add(counts[11], org.City)
+		if org.YearFounded != nil {
+			years[*org.YearFounded/yearBucket*yearBucket]++
+		}
+	}
+
+	items := func(c counter) []AggregateItem {
+		list := make([]AggregateItem, 0, len(c))
+		for v, n := range c {
+			list = append(list, AggregateItem{Value: v, Count: n})
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Count != list[j].Count {
+				return list[i].Count > list[j].Count
+			}
+			return list[i].Value < list[j].Value
+		})
+		return list
+	}
+
+	histogram := make([]YearBucket, 0, len(years))
+	for from, n := range years {
+		histogram = append(histogram, YearBucket{From: from, To: from + yearBucket - 1, Count: n})
+	}
+	sort.Slice(histogram, func(i, j int) bool { return histogram[i].From < histogram[j].From })
+
+	return AggregatesResponse{
+		Countries:         items(counts[0]),
+		Regions:           items(counts[10]),
+		Cities:            items(counts[11]),
+		SectorsPrimary:    items(counts[1]),
+		SectorsSecondary:  items(counts[2]),
+		OrganizationTypes: items(counts[3]),
+		Stages:            items(counts[4]),
+		OutcomeStatuses:   items(counts[5]),
+		Technologies:      items(counts[6]),
+		ImpactAreas:       items(counts[7]),
+		Badges:            items(counts[8]),
+		Tags:              items(counts[9]),
+		YearFounded:       histogram,
+	}
+}
@@ -0,0 +1,120 @@
+package organizations
+
+import (
+	"strings"
+	"testing"
+)
+
+// squareWithHole es un Polygon de 0..10 en lat y lng con un hueco de 4..6.
+const squareWithHole = `{"type":"Polygon","coordinates":[
+	[[0,0],[10,0],[10,10],[0,10],[0,0]],
+	[[4,4],[6,4],[6,6],[4,6],[4,4]]
+]}`
+
+func mustParseArea(t *testing.T, geojson string) area {
+	t.Helper()
+	a, err := parseArea([]byte(geojson))
+	if err != nil {
+		t.Fatalf("parseArea: %v", err)
+	}
+	return a
+}
+
+func TestAreaContains(t *testing.T) {
+	a := mustParseArea(t, squareWithHole)
+	tests := []struct {
+		name     string
+		lat, lng float64
+		want     bool
+	}{
+		{"inside", 2, 2, true},
+		{"outside", 11, 5, false},
+		{"west edge", 5, 0, true},
+		{"east edge", 5, 10, true},
+		{"north edge", 10, 5, true},
+		{"south edge", 0, 5, true},
+		{"vertex", 10, 10, true},
+		{"inside hole", 5, 5, false},
+		{"hole edge belongs to polygon", 4, 5, true},
+		{"hole vertex belongs to polygon", 6, 6, true},
+		{"between hole and outer ring", 5, 8, true},
+		{"just outside", 5, 10.000001, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.contains(tt.lat, tt.lng); got != tt.want {
+				t.Errorf("contains(%g, %g) = %v, want %v", tt.lat, tt.lng, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAreaContainsConcave(t *testing.T) {
+	// Una "U": el hueco entre los brazos queda afuera
+	a := mustParseArea(t, `{"type":"Polygon","coordinates":[[[0,0],[9,0],[9,9],[6,9],[6,3],[3,3],[3,9],[0,9],[0,0]]]}`)
+	if !a.contains(6, 1) || !a.contains(1, 1) {
+		t.Error("points inside the arms not contained")
+	}
+	if a.contains(6, 4.5) {
+		t.Error("point between the arms is contained")
+	}
+}
+
+func TestAreaMultiPolygonAcrossAntimeridian(t *testing.T) {
+	// RFC 7946 §3.1.9: lo que cruza el antimeridiano se parte en dos polígonos
+	a := mustParseArea(t, `{"type":"Feature","geometry":{"type":"MultiPolygon","coordinates":[
+		[[[170,-10],[180,-10],[180,10],[170,10],[170,-10]]],
+		[[[-180,-10],[-170,-10],[-170,10],[-180,10],[-180,-10]]]
+	]}}`)
+	tests := []struct {
+		lat, lng float64
+		want     bool
+	}{
+		{0, 175, true},
+		{0, -175, true},
+		{0, 180, true},
+		{0, -180, true},
+		{0, 0, false},
+		{0, 160, false},
+		{20, 175, false},
+	}
+	for _, tt := range tests {
+		if got := a.contains(tt.lat, tt.lng); got != tt.want {
+			t.Errorf("contains(%g, %g) = %v, want %v", tt.lat, tt.lng, got, tt.want)
+		}
+	}
+	if got := a.bbox(); got != "-10,-180,10,180" {
+		t.Errorf("bbox = %q", got)
+	}
+}
+
+func TestParseAreaErrors(t *testing.T) {
+	tests := []struct {
+		name, geojson, want string
+	}{
+		{"not JSON", `{`, "invalid GeoJSON"},
+		{"point", `{"type":"Point","coordinates":[0,0]}`, "must be a Polygon or MultiPolygon"},
+		{"feature without geometry", `{"type":"Feature"}`, "no geometry"},
+		{"open ring", `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1]]]}`, "closed"},
+		{"too few positions", `{"type":"Polygon","coordinates":[[[0,0],[1,1],[0,0]]]}`, "at least 4"},
+		{"out of range", `{"type":"Polygon","coordinates":[[[0,0],[190,0],[1,1],[0,0]]]}`, "out of range"},
+		{"degenerate ring", `{"type":"Polygon","coordinates":[[[0,0],[1,1],[2,2],[0,0]]]}`, "zero area"},
+		{"empty multipolygon", `{"type":"MultiPolygon","coordinates":[]}`, "no polygons"},
+		{"polygon without rings", `{"type":"Polygon","coordinates":[]}`, "no rings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseArea([]byte(tt.geojson))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAreaBBox(t *testing.T) {
+	a := mustParseArea(t, squareWithHole)
+	if got := a.bbox(); got != "0,0,10,10" {
+		t.Errorf("bbox = %q, want 0,0,10,10", got)
+	}
+}
@@ -1,9 +1,13 @@
 package organizations
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
+	"sync"
 )
 
 type Repository struct {
@@ -20,23 +24,27 @@ const orgSelectColumns = `
 	lat, lng, website, notes, status, created_at, updated_at,
 	description, year_founded, logo_url, linkedin_url, contact_email,
 	contact_phone, instagram_url, tags_json, technology_json,
-	impact_area_json, badge_json
+	impact_area_json, badge_json, version, slug,
+	publish_at, unpublish_at, schedule_error
 `
 
+// scanOrg lee una fila con orgSelectColumns; extra recibe columnas
+// calculadas que la consulta agregue al final (p.ej. relevancia).
 func (r *Repository) scanOrg(scanner interface {
 	Scan(dest ...any) error
-}) (*Organization, error) {
+}, extra ...any) (*Organization, error) {
 	var org Organization
-	var tagsJ, techJ, impactJ, badgeJ *string
+	var tagsJ, techJ, impactJ, badgeJ, slug *string
 
-	err := scanner.Scan(
+	dest := []any{
 		&org.ID, &org.Name, &org.OrganizationType, &org.SectorPrimary, &org.SectorSecondary,
 		&org.Stage, &org.OutcomeStatus, &org.Country, &org.Region, &org.City,
 		&org.Lat, &org.Lng, &org.Website, &org.Notes, &org.Status, &org.CreatedAt, &org.UpdatedAt,
 		&org.Description, &org.YearFounded, &org.LogoURL, &org.LinkedInURL, &org.ContactEmail,
-		&org.ContactPhone, &org.InstagramURL, &tagsJ, &techJ, &impactJ, &badgeJ,
-	)
-	if err != nil {
+		&org.ContactPhone, &org.InstagramURL, &tagsJ, &techJ, &impactJ, &badgeJ, &org.Version, &slug,
+		&org.PublishAt, &org.UnpublishAt, &org.ScheduleError,
+	}
+	if err := scanner.Scan(append(dest, extra...)...); err != nil {
 		return nil, err
 	}
 
@@ -44,125 +52,748 @@ func (r *Repository) scanOrg(scanner interface {
 	fromJSON(techJ, &org.Technology)
 	fromJSON(impactJ, &org.ImpactArea)
 	fromJSON(badgeJ, &org.Badge)
+	if slug != nil {
+		org.Slug = *slug
+	}
 
 	return &org, nil
 }
 
-func (r *Repository) Create(org *Organization) error {
-	_, err := r.DB.Exec(`
+// execer es la parte común de *sql.DB y *sql.Tx que usan las escrituras.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// Create inserta la organización junto con el snapshot de su primera versión.
+func (r *Repository) Create(org *Organization, actor string) error {
+	return r.CreateMany([]*Organization{org}, actor)
+}
+
+// CreateMany inserta todas las organizaciones (y sus snapshots) en una única
+// transacción: o se crean todas o ninguna.
+func (r *Repository) CreateMany(orgs []*Organization, actor string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, org := range orgs {
+		if err := insertOrg(tx, org); err != nil {
+			return fmt.Errorf("could not create %s: %w", org.ID, err)
+		}
+		if err := r.snapshotVersion(tx, org.ID, actor, false); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// versioned ejecuta write sobre la organización id en una transacción que
+// guarda su snapshot antes (si esa versión todavía no tiene, como las filas
+// anteriores al historial) y después de escribir. La lectura previa bloquea la
+// fila, así el snapshot posterior es exactamente lo que write dejó.
+func (r *Repository) versioned(id, actor string, write func(tx *sql.Tx) error) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.snapshotVersion(tx, id, "", true); err != nil {
+		return err
+	}
+	if err := write(tx); err != nil {
+		return err
+	}
+	if err := r.snapshotVersion(tx, id, actor, false); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// snapshotVersion guarda dentro de tx el snapshot de la fila id en su versión
+// actual. Si la versión ya tiene snapshot se conserva el primero.
+func (r *Repository) snapshotVersion(tx *sql.Tx, id, actor string, lock bool) error {
+	query := `SELECT ` + orgSelectColumns + ` FROM organizations WHERE id = ?`
+	if lock {
+		query += ` FOR UPDATE`
+	}
+	org, err := r.scanOrg(tx.QueryRow(query, id))
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(org)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT IGNORE INTO organization_versions (organization_id, version, snapshot, created_by) VALUES (?, ?, ?, ?)`,
+		org.ID, org.Version, string(data), nullIfEmpty(actor)); err != nil {
+		return fmt.Errorf("could not snapshot %s: %w", id, err)
+	}
+	return nil
+}
+
+// ExistingIDs devuelve cuáles de los ids ya existen en la tabla.
+func (r *Repository) ExistingIDs(ids []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(ids) == 0 {
+		return existing, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := r.DB.Query(`SELECT id FROM organizations WHERE id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		existing[id] = true
+	}
+	return existing, rows.Err()
+}
+
+func insertOrg(db execer, org *Organization) error {
+	_, err := db.Exec(`
 		INSERT INTO organizations (
 			id, name, organization_type, sector_primary, sector_secondary,
 			stage, outcome_status, country, region, city,
 			lat, lng, website, notes, status,
 			description, year_founded, logo_url, linkedin_url, contact_email,
 			contact_phone, instagram_url, tags_json, technology_json,
-			impact_area_json, badge_json
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			impact_area_json, badge_json, slug, publish_at, unpublish_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		org.ID, org.Name, org.OrganizationType, org.SectorPrimary, org.SectorSecondary,
 		org.Stage, org.OutcomeStatus, org.Country, org.Region, org.City,
 		org.Lat, org.Lng, org.Website, org.Notes, org.Status,
 		org.Description, org.YearFounded, org.LogoURL, org.LinkedInURL, org.ContactEmail,
 		org.ContactPhone, org.InstagramURL, toJSON(org.Tags), toJSON(org.Technology),
-		toJSON(org.ImpactArea), toJSON(org.Badge),
+		toJSON(org.ImpactArea), toJSON(org.Badge), nullIfEmpty(org.Slug), org.PublishAt, org.UnpublishAt,
 	)
 	return err
 }
 
-func (r *Repository) Update(org *Organization) error {
-	_, err := r.DB.Exec(`
-		UPDATE organizations SET 
-			name = ?, 
-			organization_type = ?, 
-			sector_primary = ?, 
-			sector_secondary = ?,
-			stage = ?, 
-			outcome_status = ?, 
-			country = ?, 
-			region = ?, 
-			city = ?,
-			lat = ?, 
-			lng = ?, 
-			website = ?, 
-			notes = ?,
-			description = ?, 
-			year_founded = ?, 
-			logo_url = ?, 
-			linkedin_url = ?, 
-			contact_email = ?,
-			contact_phone = ?, 
-			instagram_url = ?, 
-			tags_json = ?, 
-			technology_json = ?,
-			impact_area_json = ?, 
-			badge_json = ?,
-			updated_at = CURRENT_TIMESTAMP
-		WHERE id = ?`,
-		org.Name, org.OrganizationType, org.SectorPrimary, org.SectorSecondary,
-		org.Stage, org.OutcomeStatus, org.Country, org.Region, org.City,
-		org.Lat, org.Lng, org.Website, org.Notes,
-		org.Description, org.YearFounded, org.LogoURL, org.LinkedInURL, org.ContactEmail,
-		org.ContactPhone, org.InstagramURL, toJSON(org.Tags), toJSON(org.Technology),
-		toJSON(org.ImpactArea), toJSON(org.Badge),
-		org.ID,
-	)
+// UpdateFields persiste solo las columnas de los campos modificados,
+// condicionada por versión (ErrPreconditionFailed si otro cambio se adelantó),
+// y guarda el snapshot resultante.
+func (r *Repository) UpdateFields(org *Organization, changes []FieldChange, actor string) error {
+	return r.versioned(org.ID, actor, func(tx *sql.Tx) error {
+		return updateFields(tx, org, changes)
+	})
+}
+
+func updateFields(db execer, org *Organization, changes []FieldChange) error {
+	changed := make(map[string]bool, len(changes))
+	for _, c := range changes {
+		changed[c.Field] = true
+	}
+
+	set := make([]string, 0, len(changes)+2)
+	args := make([]interface{}, 0, len(changes)+2)
+	for _, f := range editableFields {
+		if changed[f.Name] {
+			set = append(set, f.Column+" = ?")
+			args = append(args, f.Value(org))
+		}
+	}
+	set = append(set, "version = version + 1", "updated_at = CURRENT_TIMESTAMP")
+	args = append(args, org.ID, org.Version)
+
+	res, err := db.Exec(`UPDATE organizations SET `+strings.Join(set, ", ")+` WHERE id = ? AND version = ?`, args...)
+	return checkVersioned(res, err)
+}
+
+// Merge guarda target con los cambios de la fusión, borra source y deja su
+// id, su slug y los alias que ya apuntaban a él como alias de target. Todo en una
+// transacción; ambas escrituras están condicionadas por versión. El último
+// estado de source queda en su historial de versiones.
+func (r *Repository) Merge(target *Organization, changes []FieldChange, source *Organization, actor string) error {
+	return r.versioned(target.ID, actor, func(tx *sql.Tx) error {
+		if err := updateFields(tx, target, changes); err != nil {
+			return err
+		}
+		if err := r.snapshotVersion(tx, source.ID, "", true); err != nil {
+			return err
+		}
+		res, err := tx.Exec(`DELETE FROM organizations WHERE id = ? AND version = ?`, source.ID, source.Version)
+		if err := checkVersioned(res, err); err != nil {
+			return err
+		}
+		if err := discardOpenRevisions(tx, source.ID, actor); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE organization_aliases SET organization_id = ? WHERE organization_id = ?`, target.ID, source.ID); err != nil {
+			return err
+		}
+		// El slug del origen también redirige, igual que su id
+		aliases := []string{source.ID}
+		if source.Slug != "" && source.Slug != source.ID {
+			aliases = append(aliases, source.Slug)
+		}
+		for _, alias := range aliases {
+			if _, err := tx.Exec(`INSERT INTO organization_aliases (alias_id, organization_id) VALUES (?, ?)`, alias, target.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindVersions lista las versiones guardadas de una organización, de la más nueva a la más vieja.
+func (r *Repository) FindVersions(id string) ([]OrganizationVersion, error) {
+	rows, err := r.DB.Query(`SELECT version, created_by, created_at FROM organization_versions WHERE organization_id = ? ORDER BY version DESC`, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not load versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make([]OrganizationVersion, 0)
+	for rows.Next() {
+		var v OrganizationVersion
+		var createdBy sql.NullString
+		if err := rows.Scan(&v.Version, &createdBy, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		v.CreatedBy = createdBy.String
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// FindVersion devuelve una versión con su snapshot.
+func (r *Repository) FindVersion(id string, version int) (*OrganizationVersion, error) {
+	var v OrganizationVersion
+	var createdBy sql.NullString
+	var data string
+	err := r.DB.QueryRow(`SELECT version, created_by, created_at, snapshot FROM organization_versions WHERE organization_id = ? AND version = ?`, id, version).
+		Scan(&v.Version, &createdBy, &v.CreatedAt, &data)
+	if err != nil {
+		return nil, err
+	}
+	v.CreatedBy = createdBy.String
+	if err := json.Unmarshal([]byte(data), &v.Snapshot); err != nil {
+		return nil, fmt.Errorf("corrupt snapshot %s@%d: %w", id, version, err)
+	}
+	return &v, nil
+}
+
+// revisionColumns son las columnas que lee scanRevision, en orden.
+const revisionColumns = `id, organization_id, base_version, status, created_by, created_at, updated_at, decided_by, decided_at, base_snapshot, snapshot`
+
+func scanRevision(scanner interface {
+	Scan(dest ...any) error
+}) (*Revision, error) {
+	var rev Revision
+	var createdBy, decidedBy sql.NullString
+	var decidedAt sql.NullTime
+	var base, proposed string
+	if err := scanner.Scan(&rev.ID, &rev.OrganizationID, &rev.BaseVersion, &rev.Status, &createdBy,
+		&rev.CreatedAt, &rev.UpdatedAt, &decidedBy, &decidedAt, &base, &proposed); err != nil {
+		return nil, err
+	}
+	rev.CreatedBy = createdBy.String
+	rev.DecidedBy = decidedBy.String
+	if decidedAt.Valid {
+		rev.DecidedAt = &decidedAt.Time
+	}
+	if err := json.Unmarshal([]byte(base), &rev.Base); err != nil {
+		return nil, fmt.Errorf("corrupt revision %d: %w", rev.ID, err)
+	}
+	if err := json.Unmarshal([]byte(proposed), &rev.Proposed); err != nil {
+		return nil, fmt.Errorf("corrupt revision %d: %w", rev.ID, err)
+	}
+	rev.Changes = diffOrganizations(rev.Base, rev.Proposed)
+	return &rev, nil
+}
+
+// FindRevisions lista las revisiones de una organización, de la más nueva a la más vieja.
+// status vacío = todas.
+func (r *Repository) FindRevisions(id string, status RevisionStatus) ([]Revision, error) {
+	query := `SELECT ` + revisionColumns + ` FROM organization_revisions WHERE 1=1`
+	args := make([]interface{}, 0, 2)
+	if id != "" {
+		query += ` AND organization_id = ?`
+		args = append(args, id)
+	}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	rows, err := r.DB.Query(query+` ORDER BY id DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not load revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]Revision, 0)
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *rev)
+	}
+	return revisions, rows.Err()
+}
+
+// FindRevision devuelve una revisión de la organización id.
+func (r *Repository) FindRevision(id string, revisionID int64) (*Revision, error) {
+	row := r.DB.QueryRow(`SELECT `+revisionColumns+` FROM organization_revisions WHERE organization_id = ? AND id = ?`, id, revisionID)
+	return scanRevision(row)
+}
+
+// FindOpenRevision devuelve la revisión DRAFT o IN_REVIEW de la organización
+// (sql.ErrNoRows si no hay). El índice ux_organization_revisions_open
+// garantiza que hay a lo sumo una.
+func (r *Repository) FindOpenRevision(id string) (*Revision, error) {
+	row := r.DB.QueryRow(`SELECT `+revisionColumns+` FROM organization_revisions WHERE open_key = ?`, id)
+	return scanRevision(row)
+}
+
+// SaveRevision crea la revisión (ID 0) o reemplaza el snapshot propuesto de
+// una revisión que sigue en DRAFT.
+func (r *Repository) SaveRevision(rev *Revision) error {
+	proposed, err := json.Marshal(rev.Proposed)
+	if err != nil {
+		return err
+	}
+	if rev.ID != 0 {
+		res, err := r.DB.Exec(`UPDATE organization_revisions SET snapshot = ? WHERE id = ? AND status = ?`, string(proposed), rev.ID, RevisionDraft)
+		return checkVersioned(res, err)
+	}
+
+	base, err := json.Marshal(rev.Base)
+	if err != nil {
+		return err
+	}
+	res, err := r.DB.Exec(`INSERT INTO organization_revisions (organization_id, base_version, base_snapshot, snapshot, status, created_by) VALUES (?, ?, ?, ?, ?, ?)`,
+		rev.OrganizationID, rev.BaseVersion, string(base), string(proposed), RevisionDraft, nullIfEmpty(rev.CreatedBy))
+	if err != nil {
+		return err
+	}
+	rev.ID, err = res.LastInsertId()
 	return err
 }
 
-func (r *Repository) Delete(id string) error {
-	_, err := r.DB.Exec(`DELETE FROM organizations WHERE id = ?`, id)
+// UpdateRevisionStatus mueve la revisión de from a to. Si otro cambio se
+// adelantó (ya no está en from) devuelve ErrPreconditionFailed.
+func (r *Repository) UpdateRevisionStatus(revisionID int64, from, to RevisionStatus, actor string) error {
+	return updateRevisionStatus(r.DB, revisionID, from, to, actor)
+}
+
+// ApproveRevision aplica changes sobre la organización y cierra la revisión
+// en una única transacción.
+func (r *Repository) ApproveRevision(org *Organization, changes []FieldChange, rev *Revision, actor string) error {
+	return r.versioned(org.ID, actor, func(tx *sql.Tx) error {
+		if len(changes) > 0 {
+			if err := updateFields(tx, org, changes); err != nil {
+				return err
+			}
+		}
+		return updateRevisionStatus(tx, rev.ID, rev.Status, RevisionApproved, actor)
+	})
+}
+
+// discardOpenRevisions cierra sin aplicar la revisión abierta de la organización, si la hay.
+func discardOpenRevisions(db execer, id, actor string) error {
+	_, err := db.Exec(`UPDATE organization_revisions SET status = ?, decided_by = ?, decided_at = CURRENT_TIMESTAMP WHERE open_key = ?`,
+		RevisionDiscarded, nullIfEmpty(actor), id)
 	return err
 }
 
+func updateRevisionStatus(db execer, revisionID int64, from, to RevisionStatus, actor string) error {
+	query := `UPDATE organization_revisions SET status = ? WHERE id = ? AND status = ?`
+	args := []interface{}{to, revisionID, from}
+	if to == RevisionApproved || to == RevisionDiscarded {
+		query = `UPDATE organization_revisions SET status = ?, decided_by = ?, decided_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?`
+		args = []interface{}{to, nullIfEmpty(actor), revisionID, from}
+	}
+	res, err := db.Exec(query, args...)
+	return checkVersioned(res, err)
+}
+
+// ResolveAlias devuelve el id vigente de una organización fusionada.
+func (r *Repository) ResolveAlias(id string) (string, error) {
+	var target string
+	err := r.DB.QueryRow(`SELECT organization_id FROM organization_aliases WHERE alias_id = ?`, id).Scan(&target)
+	return target, err
+}
+
+// FindAliases devuelve los ids y slugs que se fusionaron en la organización.
+func (r *Repository) FindAliases(id string) ([]string, error) {
+	rows, err := r.DB.Query(`SELECT alias_id FROM organization_aliases WHERE organization_id = ? ORDER BY created_at, alias_id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := make([]string, 0)
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, rows.Err()
+}
+
+// Delete borra la organización y cierra su revisión abierta, si quedó alguna.
+func (r *Repository) Delete(id string, version int, actor string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM organizations WHERE id = ? AND version = ?`, id, version)
+	if err := checkVersioned(res, err); err != nil {
+		return err
+	}
+	if err := discardOpenRevisions(tx, id, actor); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// checkVersioned traduce "0 filas afectadas" de una escritura condicionada
+// por versión en ErrPreconditionFailed.
+func checkVersioned(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
 func (r *Repository) FindByID(id string) (*Organization, error) {
 	row := r.DB.QueryRow(`SELECT `+orgSelectColumns+` FROM organizations WHERE id = ?`, id)
 	return r.scanOrg(row)
 }
 
-func (r *Repository) UpdateStatus(id string, status OrganizationStatus) error {
-	_, err := r.DB.Exec(`UPDATE organizations SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, status, id)
+// UpdateStatus cambia el status solo si la versión sigue siendo version, para
+// que dos transiciones concurrentes no se pisen.
+func (r *Repository) UpdateStatus(id string, status OrganizationStatus, version int, actor string) error {
+	return r.versioned(id, actor, func(tx *sql.Tx) error {
+		if err := updateStatus(tx, id, status, version); err != nil {
+			return err
+		}
+		// Solo una organización publicada tiene revisiones abiertas
+		if status != StatusPublished {
+			return discardOpenRevisions(tx, id, actor)
+		}
+		return nil
+	})
+}
+
+func updateStatus(db execer, id string, status OrganizationStatus, version int) error {
+	// Publicar consume publish_at y archivar unpublish_at, y con eso el último
+	// fallo del scheduler; los demás cambios de estado no tocan la programación.
+	res, err := db.Exec(`
+		UPDATE organizations SET
+			status = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP,
+			publish_at = IF(? = 'PUBLISHED', NULL, publish_at),
+			unpublish_at = IF(? = 'ARCHIVED', NULL, unpublish_at),
+			schedule_error = IF(? IN ('PUBLISHED', 'ARCHIVED'), NULL, schedule_error),
+			schedule_failed_at = IF(? IN ('PUBLISHED', 'ARCHIVED'), NULL, schedule_failed_at)
+		WHERE id = ? AND version = ?`, status, status, status, status, status, id, version)
+	return checkVersioned(res, err)
+}
+
+// FindDueSchedules devuelve hasta limit publicaciones (no publicadas con
+// publish_at vencido) y archivados (PUBLISHED con unpublish_at vencido)
+// pendientes. Una DRAFT también vuelve, para que el fallo quede registrado
+// en vez de esperar en silencio. Las que fallaron no vuelven hasta que la
+// organización se edite.
+func (r *Repository) FindDueSchedules(limit int) ([]ScheduledTransition, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, version, status, 'PUBLISHED', publish_at FROM organizations
+		WHERE status <> 'PUBLISHED' AND publish_at <= UTC_TIMESTAMP()
+			AND (schedule_failed_at IS NULL OR updated_at > schedule_failed_at)
+		UNION ALL
+		SELECT id, version, status, 'ARCHIVED', unpublish_at FROM organizations
+		WHERE status = 'PUBLISHED' AND unpublish_at <= UTC_TIMESTAMP()
+			AND (schedule_failed_at IS NULL OR updated_at > schedule_failed_at)
+		ORDER BY 5
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("could not load scheduled transitions: %w", err)
+	}
+	defer rows.Close()
+
+	due := make([]ScheduledTransition, 0)
+	for rows.Next() {
+		var t ScheduledTransition
+		if err := rows.Scan(&t.ID, &t.Version, &t.From, &t.To, &t.At); err != nil {
+			return nil, err
+		}
+		due = append(due, t)
+	}
+	return due, rows.Err()
+}
+
+// RecordScheduleFailure guarda el motivo por el que el scheduler no pudo
+// ejecutar la transición. No cuenta como edición: versión y updated_at no cambian.
+func (r *Repository) RecordScheduleFailure(id, message string) error {
+	if len(message) > maxScheduleError {
+		message = message[:maxScheduleError]
+	}
+	_, err := r.DB.Exec(`UPDATE organizations SET schedule_error = ?, schedule_failed_at = CURRENT_TIMESTAMP, updated_at = updated_at WHERE id = ?`, message, id)
 	return err
 }
 
+// WithLock ejecuta fn solo si obtiene sin esperar el lock con nombre de
+// MariaDB (GET_LOCK). El lock es de la sesión, por eso toma una conexión
+// dedicada; si la conexión se cae, MariaDB lo libera. Si lo tiene otra
+// instancia devuelve nil sin ejecutar fn.
+func (r *Repository) WithLock(ctx context.Context, name string, fn func()) error {
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, 0)`, name).Scan(&acquired); err != nil {
+		return err
+	}
+	if acquired.Int64 != 1 {
+		return nil
+	}
+	defer func() {
+		var released sql.NullInt64
+		conn.QueryRowContext(context.Background(), `SELECT RELEASE_LOCK(?)`, name).Scan(&released)
+	}()
+
+	fn()
+	return nil
+}
+
 func (r *Repository) FindPublishedByID(id string) (*Organization, error) {
 	row := r.DB.QueryRow(`SELECT `+orgSelectColumns+` FROM organizations WHERE id = ? AND status = 'PUBLISHED'`, id)
 	return r.scanOrg(row)
 }
 
-func (r *Repository) FindFiltered(params map[string]string) ([]Organization, error) {
-	query := `SELECT ` + orgSelectColumns + ` FROM organizations WHERE 1=1`
-	args := make([]interface{}, 0)
+// FindPublishedBySlug busca una organización publicada por su slug.
+func (r *Repository) FindPublishedBySlug(slug string) (*Organization, error) {
+	row := r.DB.QueryRow(`SELECT `+orgSelectColumns+` FROM organizations WHERE slug = ? AND status = 'PUBLISHED'`, slug)
+	return r.scanOrg(row)
+}
 
-	whereSQL, args := r.buildWhereClause(params)
-	query += whereSQL + " ORDER BY updated_at DESC"
+// TakenSlugs devuelve los slugs ya usados (por organizaciones o como alias
+// de fusionadas) iguales a base o de la forma base-N. Los ids también
+// cuentan: la ruta pública los busca antes que a los slugs.
+func (r *Repository) TakenSlugs(base string) (map[string]bool, error) {
+	rows, err := r.DB.Query(`
+		SELECT slug FROM organizations WHERE slug = ? OR slug LIKE ?
+		UNION SELECT id FROM organizations WHERE id = ? OR id LIKE ?
+		UNION SELECT alias_id FROM organization_aliases WHERE alias_id = ? OR alias_id LIKE ?`,
+		base, base+"-%", base, base+"-%", base, base+"-%")
+	if err != nil {
+		return nil, fmt.Errorf("could not load slugs: %w", err)
+	}
+	defer rows.Close()
+
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		taken[slug] = true
+	}
+	return taken, rows.Err()
+}
+
+// FindWithoutSlug devuelve id y nombre de las organizaciones sin slug.
+func (r *Repository) FindWithoutSlug() ([]Organization, error) {
+	rows, err := r.DB.Query(`SELECT id, name FROM organizations WHERE slug IS NULL OR slug = '' ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := make([]Organization, 0)
+	for rows.Next() {
+		var org Organization
+		if err := rows.Scan(&org.ID, &org.Name); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, rows.Err()
+}
+
+// SetSlug asigna el slug sin tocar version ni updated_at (no es una edición).
+func (r *Repository) SetSlug(id, slug string) error {
+	_, err := r.DB.Exec(`UPDATE organizations SET slug = ?, updated_at = updated_at WHERE id = ?`, slug, id)
+	return err
+}
+
+func (r *Repository) FindFiltered(params map[string]string) ([]Organization, error) {
+	orgs := make([]Organization, 0)
+	err := r.StreamFiltered(params, func(org *Organization) error {
+		orgs = append(orgs, *org)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
 
+// StreamFiltered recorre las organizaciones que cumplen los filtros de a una,
+// sin acumularlas en memoria (exportaciones grandes). Si fn devuelve error
+// se corta la iteración.
+func (r *Repository) StreamFiltered(params map[string]string, fn func(org *Organization) error) error {
+	var page pageQuery
 	if limitStr := params["limit"]; limitStr != "" {
 		if limit, err := parseInt(limitStr); err == nil {
-			query += " LIMIT ?"
-			args = append(args, limit)
+			page.limit = limit
 			if offsetStr := params["offset"]; offsetStr != "" {
 				if offset, err := parseInt(offsetStr); err == nil {
-					query += " OFFSET ?"
-					args = append(args, offset)
+					page.offset = offset
 				}
 			}
 		}
 	}
+	return r.streamPage(params, page, fn)
+}
 
-	rows, err := r.DB.Query(query, args...)
+// FindPage devuelve una página de resultados y el total de coincidencias.
+// Con el orden por defecto pagina por keyset sobre (updated_at, id); con
+// orden por relevancia o distancia el cursor lleva un offset.
+func (r *Repository) FindPage(params map[string]string, req PageRequest) (*PageResult, error) {
+	cur, err := decodeCursor(req.Cursor)
+	if err != nil {
+		return nil, invalid(err)
+	}
+
+	whereSQL, args := r.buildWhereClause(params)
+	result := &PageResult{Items: make([]Organization, 0, req.Limit)}
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM organizations WHERE 1=1`+whereSQL, args...).Scan(&result.Total); err != nil {
+		return nil, err
+	}
+
+	keyset := len(searchTerms(params["q"])) == 0 && params["sort"] != sortDistance
+	page := pageQuery{limit: req.Limit + 1, offset: req.Offset} // una fila extra indica si hay otra página
+	if keyset {
+		page.after = cur
+	} else if cur != nil {
+		page.offset = cur.Offset
+	}
+
+	err = r.streamPage(params, page, func(org *Organization) error {
+		result.Items = append(result.Items, *org)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	if len(result.Items) > req.Limit {
+		result.Items = result.Items[:req.Limit]
+		last := result.Items[len(result.Items)-1]
+		next := &pageCursor{Offset: page.offset + req.Limit}
+		if keyset {
+			next = &pageCursor{UpdatedAt: &last.UpdatedAt, ID: last.ID}
+		}
+		result.NextCursor = encodeCursor(next)
+	}
+	return result, nil
+}
+
+// streamPage ejecuta la consulta filtrada con el recorte de página indicado.
+func (r *Repository) streamPage(params map[string]string, page pageQuery, fn func(org *Organization) error) error {
+	selectSQL := orgSelectColumns
+	args := make([]interface{}, 0)
+	orderSQL := " ORDER BY updated_at DESC, id DESC"
+
+	// Con búsqueda de texto se ordena por relevancia
+	terms := searchTerms(params["q"])
+	if len(terms) > 0 {
+		selectSQL += ", " + searchMatchExpr + " AS relevance"
+		args = append(args, booleanQuery(terms))
+		orderSQL = " ORDER BY relevance DESC, updated_at DESC, id DESC"
+	}
+
+	// Con ?near= cada fila lleva su distancia al punto
+	near, _ := parseNear(params)
+	if near != nil {
+		selectSQL += ", " + distanceExpr + " AS distance_km"
+		args = append(args, near.distanceArgs()...)
+		if params["sort"] == sortDistance {
+			orderSQL = " ORDER BY distance_km ASC, id ASC"
+		}
+	}
+
+	whereSQL, whereArgs := r.buildWhereClause(params)
+	args = append(args, whereArgs...)
+	keysetSQL, keysetArgs := keysetCondition(page.after)
+	whereSQL += keysetSQL
+	args = append(args, keysetArgs...)
+	query := `SELECT ` + selectSQL + ` FROM organizations WHERE 1=1` + whereSQL + orderSQL
+
+	if page.limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, page.limit)
+		if page.offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, page.offset)
+		}
+	}
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return err
+	}
 	defer rows.Close()
 
-	orgs := make([]Organization, 0)
 	for rows.Next() {
-		org, err := r.scanOrg(rows)
+		var relevance, distance float64
+		var extra []any
+		if len(terms) > 0 {
+			extra = append(extra, &relevance)
+		}
+		if near != nil {
+			extra = append(extra, &distance)
+		}
+
+		org, err := r.scanOrg(rows, extra...)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if len(terms) > 0 {
+			org.Relevance = &relevance
+			org.Highlights = searchHighlights(org, terms)
+		}
+		if near != nil {
+			km := math.Round(distance*100) / 100
+			org.DistanceKm = &km
+		}
+		if err := fn(org); err != nil {
+			return err
 		}
-		orgs = append(orgs, *org)
 	}
-	return orgs, nil
+	return rows.Err()
 }
 
 // Para compatibilidad con código existente que usa FindPublishedFiltered
@@ -192,12 +823,8 @@ func (r *Repository) FindAll() ([]Organization, error) {
 	return r.FindFiltered(map[string]string{})
 }
 
-func (r *Repository) UpdateCoordinates(id string, lat, lng float64) error {
-	_, err := r.DB.Exec(`UPDATE organizations SET lat = ?, lng = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, lat, lng, id)
-	return err
-}
-
-// Aggregates remains the same but could use FindFiltered logic if needed.
+// GetAggregates calcula los facets y el histograma de yearFounded. Cada facet
+// excluye su propio filtro y las consultas corren en paralelo.
 func (r *Repository) GetAggregates(params map[string]string) (*AggregatesResponse, error) {
 	// Ensure we only aggregate published orgs unless specified otherwise
 	if params == nil {
@@ -207,43 +834,185 @@ func (r *Repository) GetAggregates(params map[string]string) (*AggregatesRespons
 	if params["status"] == "" {
 		params["status"] = string(StatusPublished)
 	}
+	bucket, err := parseYearBucket(params)
+	if err != nil {
+		return nil, invalid(err)
+	}
 
-	whereSQL, args := r.buildWhereClause(params)
+	resp := &AggregatesResponse{}
+	errs := make(chan error, len(facets)+1)
+	var wg sync.WaitGroup
 
-	resp := &AggregatesResponse{
-		Countries:         make([]AggregateItem, 0),
-		SectorsPrimary:    make([]AggregateItem, 0),
-		SectorsSecondary:  make([]AggregateItem, 0),
-		OrganizationTypes: make([]AggregateItem, 0),
-		Stages:            make([]AggregateItem, 0),
-		OutcomeStatuses:   make([]AggregateItem, 0),
+	for _, f := range facets {
+		wg.Add(1)
+		go func(f facetSpec) {
+			defer wg.Done()
+			whereSQL, args := r.buildWhereClause(withoutFilter(params, f.Param))
+			var items []AggregateItem
+			var err error
+			if f.Multi {
+				items, err = r.fetchMultiValueAggregation(f.Column, whereSQL, args)
+			} else {
+				items, err = r.fetchAggregation(f.Column, f.IgnoreEmpty, whereSQL, args)
+			}
+			*f.Field(resp) = items
+			errs <- err
+		}(f)
 	}
-	var err error
-	resp.Countries, err = r.fetchAggregation("country", false, whereSQL, args)
-	if err != nil {
-		return nil, err
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		whereSQL, args := r.buildWhereClause(params)
+		var err error
+		resp.YearFounded, err = r.fetchYearHistogram(bucket, whereSQL, args)
+		errs <- err
+	}()
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return nil, err
+		}
 	}
-	resp.SectorsPrimary, err = r.fetchAggregation("sector_primary", false, whereSQL, args)
-	if err != nil {
-		return nil, err
+	return resp, nil
+}
+
+// DuplicateRecords devuelve los datos mínimos de todas las organizaciones
+// para la detección de duplicados.
+func (r *Repository) DuplicateRecords() ([]duplicateRecord, error) {
+	return r.queryDuplicateRecords("", nil)
+}
+
+// DuplicateRecordsFor devuelve solo los registros que podrían coincidir con
+// org: mismo país o website/LinkedIn que contiene su dominio o página.
+func (r *Repository) DuplicateRecordsFor(org *Organization) ([]duplicateRecord, error) {
+	keys := keysOf(recordOf(org))
+	conds := []string{"country = ?"}
+	args := []interface{}{org.Country}
+	if keys.domain != "" {
+		conds = append(conds, "website LIKE ?")
+		args = append(args, "%"+keys.domain+"%")
+	}
+	if keys.linkedin != "" {
+		conds = append(conds, "linkedin_url LIKE ?")
+		args = append(args, "%"+keys.linkedin+"%")
 	}
-	resp.SectorsSecondary, err = r.fetchAggregation("sector_secondary", true, whereSQL, args)
+	return r.queryDuplicateRecords(" WHERE "+strings.Join(conds, " OR "), args)
+}
+
+func (r *Repository) queryDuplicateRecords(whereSQL string, args []interface{}) ([]duplicateRecord, error) {
+	rows, err := r.DB.Query(`SELECT id, name, country, website, linkedin_url, status FROM organizations`+whereSQL, args...)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("could not load duplicate candidates: %w", err)
 	}
-	resp.OrganizationTypes, err = r.fetchAggregation("organization_type", false, whereSQL, args)
+	defer rows.Close()
+
+	records := make([]duplicateRecord, 0)
+	for rows.Next() {
+		var rec duplicateRecord
+		if err := rows.Scan(&rec.ID, &rec.Name, &rec.Country, &rec.Website, &rec.LinkedInURL, &rec.Status); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// Timeseries cuenta organizaciones por período (y por grupo si q.GroupBy).
+// "founded" usa year_founded; "published" usa la primera transición a
+// PUBLISHED del audit log, cualquiera sea el estado actual (una archivada
+// sigue contando), y created_at para las publicadas sin registro.
+func (r *Repository) Timeseries(params map[string]string, q TimeseriesQuery) (*TimeseriesResponse, error) {
+	groupSQL := "''"
+	if q.column != "" {
+		groupSQL = "COALESCE(" + q.column + ", '')"
+	}
+
+	whereSQL, whereArgs := r.buildWhereClause(params)
+	args := make([]interface{}, 0)
+	var query string
+	switch q.Metric {
+	case metricPublished:
+		query = `SELECT ` + groupSQL + ` AS grp, DATE_FORMAT(COALESCE(p.published_at, created_at), '%Y-%m') AS period, COUNT(*)
+			FROM organizations
+			LEFT JOIN (
+				SELECT entity_id, MIN(performed_at) AS published_at FROM audit_logs
+				WHERE entity_type = ? AND to_status = ? GROUP BY entity_id
+			) p ON p.entity_id = organizations.id
+			WHERE (p.published_at IS NOT NULL OR organizations.status = ?)` + whereSQL
+		args = append(args, auditEntityType, string(StatusPublished), string(StatusPublished))
+	default:
+		query = `SELECT ` + groupSQL + ` AS grp, CAST(year_founded AS CHAR) AS period, COUNT(*)
+			FROM organizations WHERE year_founded IS NOT NULL` + whereSQL
+	}
+	query += " GROUP BY grp, period ORDER BY grp, period"
+	args = append(args, whereArgs...)
+
+	rows, err := r.DB.Query(query, args...)
 	if err != nil {
+		return nil, fmt.Errorf("could not load timeseries: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]timeseriesRow, 0)
+	for rows.Next() {
+		var row timeseriesRow
+		if err := rows.Scan(&row.Group, &row.Period, &row.Count); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	resp.Stages, err = r.fetchAggregation("stage", true, whereSQL, args)
+	return buildSeries(q, result), nil
+}
+
+// fetchYearHistogram agrupa year_founded en tramos de bucket años.
+func (r *Repository) fetchYearHistogram(bucket int, whereSQL string, args []interface{}) ([]YearBucket, error) {
+	query := "SELECT FLOOR(year_founded / ?) * ? AS bucket, COUNT(*) AS count FROM organizations WHERE 1=1 " + whereSQL +
+		" AND year_founded IS NOT NULL GROUP BY bucket ORDER BY bucket"
+
+	rows, err := r.DB.Query(query, append([]interface{}{bucket, bucket}, args...)...)
 	if err != nil {
 		return nil, err
 	}
-	resp.OutcomeStatuses, err = r.fetchAggregation("outcome_status", false, whereSQL, args)
+	defer rows.Close()
+	buckets := make([]YearBucket, 0)
+	for rows.Next() {
+		var b YearBucket
+		if err := rows.Scan(&b.From, &b.Count); err != nil {
+			return nil, err
+		}
+		b.To = b.From + bucket - 1
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+// fetchMultiValueAggregation cuenta organizaciones por cada valor de un
+// arreglo JSON (technology_json, tags_json, ...).
+func (r *Repository) fetchMultiValueAggregation(column string, whereSQL string, args []interface{}) ([]AggregateItem, error) {
+	query := "SELECT jt.value AS value, COUNT(DISTINCT organizations.id) AS count FROM organizations, " +
+		jsonValuesTable("organizations."+column) + " WHERE 1=1 " + whereSQL +
+		" AND jt.value IS NOT NULL AND jt.value <> '' GROUP BY jt.value ORDER BY count DESC, value ASC"
+
+	rows, err := r.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
-	return resp, nil
+	defer rows.Close()
+	items := make([]AggregateItem, 0)
+	for rows.Next() {
+		var i AggregateItem
+		if err := rows.Scan(&i.Value, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	return items, rows.Err()
 }
 
 func (r *Repository) fetchAggregation(column string, ignoreEmpty bool, whereSQL string, args []interface{}) ([]AggregateItem, error) {
@@ -276,42 +1045,54 @@ func (r *Repository) fetchAggregation(column string, ignoreEmpty bool, whereSQL
 	return items, nil
 }
 
+// FindMapPoints devuelve solo id, nombre, tipo, sector y coordenadas de las
+// organizaciones que cumplen los filtros (y tienen coordenadas).
+func (r *Repository) FindMapPoints(params map[string]string) ([]MapPoint, error) {
+	whereSQL, args := r.buildWhereClause(params)
+	query := `SELECT id, name, organization_type, sector_primary, lat, lng
+		FROM organizations WHERE lat IS NOT NULL AND lng IS NOT NULL` + whereSQL
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not load map points: %w", err)
+	}
+	defer rows.Close()
+
+	points := make([]MapPoint, 0)
+	for rows.Next() {
+		var p MapPoint
+		if err := rows.Scan(&p.ID, &p.Name, &p.OrganizationType, &p.SectorPrimary, &p.Lat, &p.Lng); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
 func (r *Repository) buildWhereClause(params map[string]string) (string, []interface{}) {
 	var query string
 	args := make([]interface{}, 0)
 
-	if status := params["status"]; status != "" {
-		query += " AND status = ?"
-		args = append(args, status)
-	}
-	if country := params["country"]; country != "" {
-		query += " AND country = ?"
-		args = append(args, country)
-	}
-	if sectorPrimary := params["sectorPrimary"]; sectorPrimary != "" {
-		query += " AND sector_primary = ?"
-		args = append(args, sectorPrimary)
-	}
-	if sectorSecondary := params["sectorSecondary"]; sectorSecondary != "" {
-		query += " AND sector_secondary = ?"
-		args = append(args, sectorSecondary)
+	for _, f := range scalarFilters {
+		cond, condArgs := scalarCondition(f, params)
+		query += cond
+		args = append(args, condArgs...)
 	}
-	if organizationType := params["organizationType"]; organizationType != "" {
-		query += " AND organization_type = ?"
-		args = append(args, organizationType)
-	}
-	if stage := params["stage"]; stage != "" {
-		query += " AND stage = ?"
-		args = append(args, stage)
-	}
-	if outcomeStatus := params["outcomeStatus"]; outcomeStatus != "" {
-		query += " AND outcome_status = ?"
-		args = append(args, outcomeStatus)
+	for _, f := range multiValueFilters {
+		cond, condArgs := multiValueCondition(f, params)
+		query += cond
+		args = append(args, condArgs...)
 	}
 	if q := params["q"]; q != "" {
-		query += " AND (name LIKE ? OR city LIKE ? OR region LIKE ? OR country LIKE ? OR description LIKE ?)"
-		like := "%" + q + "%"
-		args = append(args, like, like, like, like, like)
+		if terms := searchTerms(q); len(terms) > 0 {
+			query += " AND " + searchMatchExpr
+			args = append(args, booleanQuery(terms))
+		} else {
+			// Términos demasiado cortos para el índice FULLTEXT
+			query += " AND (name LIKE ? OR city LIKE ? OR region LIKE ? OR country LIKE ? OR description LIKE ?)"
+			like := "%" + q + "%"
+			args = append(args, like, like, like, like, like)
+		}
 	}
 	if params["onlyMappable"] == "true" {
 		query += " AND lat IS NOT NULL AND lng IS NOT NULL"
@@ -323,5 +1104,10 @@ func (r *Repository) buildWhereClause(params map[string]string) (string, []inter
 			args = append(args, parts[0], parts[1], parts[2], parts[3])
 		}
 	}
+	if near, err := parseNear(params); err == nil && near != nil {
+		cond, condArgs := near.condition()
+		query += cond
+		args = append(args, condArgs...)
+	}
 	return query, args
 }
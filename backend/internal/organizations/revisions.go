@@ -0,0 +1,59 @@
+package organizations
+
+import (
+	"errors"
+	"time"
+)
+
+// RevisionStatus es el estado de una revisión de una organización publicada.
+// Sigue su propio ciclo: DRAFT -> IN_REVIEW -> APPROVED, o DISCARDED.
+type RevisionStatus string
+
+const (
+	RevisionDraft     RevisionStatus = "DRAFT"
+	RevisionInReview  RevisionStatus = "IN_REVIEW"
+	RevisionApproved  RevisionStatus = "APPROVED"
+	RevisionDiscarded RevisionStatus = "DISCARDED"
+)
+
+// ErrRevisionInReview indica que la revisión abierta ya se envió a revisión
+// y no acepta más cambios hasta que se apruebe o descarte.
+var ErrRevisionInReview = errors.New("the pending revision is in review; approve or discard it before editing again")
+
+// Revision es un cambio propuesto sobre una organización publicada. El
+// público sigue viendo la fila de organizations hasta que se aprueba.
+// Changes va de Base (lo publicado al abrir la revisión) a Proposed.
+type Revision struct {
+	ID             int64          `json:"id"`
+	OrganizationID string         `json:"organizationId"`
+	BaseVersion    int            `json:"baseVersion"`
+	Status         RevisionStatus `json:"status"`
+	CreatedBy      string         `json:"createdBy,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+	DecidedBy      string         `json:"decidedBy,omitempty"`
+	DecidedAt      *time.Time     `json:"decidedAt,omitempty"`
+	Changes        []FieldChange  `json:"changes"`
+	Proposed       *Organization  `json:"proposed,omitempty"`
+	Base           *Organization  `json:"-"`
+}
+
+// isOpen informa si la revisión todavía puede aprobarse o descartarse.
+func (r *Revision) isOpen() bool {
+	return r.Status == RevisionDraft || r.Status == RevisionInReview
+}
+
+// appliedTo devuelve current con los campos que la revisión cambió respecto
+// de su base. Lo que cambió en current mientras tanto y la revisión no tocó
+// se conserva.
+func (r *Revision) appliedTo(current *Organization) (*Organization, error) {
+	doc, err := toDocument(r.Proposed)
+	if err != nil {
+		return nil, err
+	}
+	patch := make(map[string]any)
+	for _, c := range diffOrganizations(r.Base, r.Proposed) {
+		patch[c.Field] = doc[c.Field] // ausente = null, el merge patch lo borra
+	}
+	return mergeOrganization(current, patch)
+}
@@ -0,0 +1,36 @@
+package organizations
+
+import "time"
+
+const (
+	// schedulerLockName es el GET_LOCK que comparten todas las instancias de la API.
+	schedulerLockName = "organizations_scheduler"
+	// schedulerActor figura como autor de las transiciones programadas en auditoría.
+	schedulerActor = "scheduler"
+	// scheduleBatchSize limita las transiciones que se ejecutan por ronda.
+	scheduleBatchSize = 100
+	// maxScheduleError es el largo de la columna schedule_error.
+	maxScheduleError = 1000
+)
+
+// ScheduledTransition es una publicación o archivado programado que ya venció.
+type ScheduledTransition struct {
+	ID      string
+	Version int
+	From    OrganizationStatus
+	To      OrganizationStatus
+	At      time.Time // publishAt o unpublishAt, en UTC
+}
+
+// consumeSchedule devuelve org como queda después de pasar a to: publicar
+// consume publishAt y archivar unpublishAt.
+func consumeSchedule(org *Organization, to OrganizationStatus) *Organization {
+	after := *org
+	switch to {
+	case StatusPublished:
+		after.PublishAt = nil
+	case StatusArchived:
+		after.UnpublishAt = nil
+	}
+	return &after
+}
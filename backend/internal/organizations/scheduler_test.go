@@ -0,0 +1,37 @@
+package organizations
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConsumeSchedule(t *testing.T) {
+	publishAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	unpublishAt := publishAt.AddDate(0, 1, 0)
+	org := &Organization{ID: "o1", PublishAt: &publishAt, UnpublishAt: &unpublishAt}
+
+	tests := []struct {
+		to   OrganizationStatus
+		want []string
+	}{
+		{StatusPublished, []string{"publishAt"}},
+		{StatusArchived, []string{"unpublishAt"}},
+		{StatusInReview, nil},
+		{StatusDraft, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, c := range diffOrganizations(org, consumeSchedule(org, tt.to)) {
+			if c.NewValue != nil {
+				t.Errorf("%s: %s cleared to %q, want null", tt.to, c.Field, *c.NewValue)
+			}
+			got = append(got, c.Field)
+		}
+		if len(got) != len(tt.want) || (len(got) == 1 && got[0] != tt.want[0]) {
+			t.Errorf("consumeSchedule(%s) changed %v, want %v", tt.to, got, tt.want)
+		}
+	}
+	if org.PublishAt == nil || org.UnpublishAt == nil {
+		t.Error("consumeSchedule modified its argument")
+	}
+}
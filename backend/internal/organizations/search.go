@@ -0,0 +1,189 @@
+package organizations
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// searchMatchColumns son las columnas del índice FULLTEXT (migración 008).
+// MATCH(...) debe listar exactamente las mismas, en el mismo orden.
+const searchMatchColumns = "name, description, city, region, country, tags_json, technology_json"
+
+const searchMatchExpr = "MATCH(" + searchMatchColumns + ") AGAINST (? IN BOOLEAN MODE)"
+
+// searchMinTermLen es el largo mínimo de término que indexa InnoDB (innodb_ft_min_token_size).
+const searchMinTermLen = 3
+
+// searchSnippetRadius es la cantidad de caracteres de contexto a cada lado del término.
+const searchSnippetRadius = 60
+
+// stemSuffixes son terminaciones frecuentes (español e inglés) que se quitan
+// para buscar por prefijo: "inteligente" -> "intelig*" encuentra "inteligencia".
+var stemSuffixes = []string{
+	"aciones", "amiento", "imiento", "ciones", "mente", "encia", "ancia",
+	"iendo", "cion", "ente", "ante", "idad", "ismo", "ista", "ando",
+	"ado", "ada", "ido", "ida", "ing", "es", "ed", "s", "o", "a", "e",
+}
+
+// searchTerms separa q en términos, los pasa a minúscula sin acentos y los reduce a su raíz.
+func searchTerms(q string) []string {
+	words := strings.FieldsFunc(foldText(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	seen := make(map[string]bool)
+	for _, w := range words {
+		t := stem(w)
+		if len([]rune(t)) < searchMinTermLen || seen[t] {
+			continue
+		}
+		seen[t] = true
+		terms = append(terms, t)
+	}
+	return terms
+}
+
+// stem quita una terminación conocida si la raíz resultante conserva al menos 4 letras.
+func stem(word string) string {
+	for _, suffix := range stemSuffixes {
+		if strings.HasSuffix(word, suffix) && len([]rune(word))-len([]rune(suffix)) >= 4 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+// booleanQuery arma la expresión para MATCH ... IN BOOLEAN MODE: cualquier
+// término (OR) como prefijo. MATCH devuelve más puntaje a quien coincide en más términos.
+func booleanQuery(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		parts[i] = t + "*"
+	}
+	return strings.Join(parts, " ")
+}
+
+// foldText pasa a minúscula y quita acentos, runa por runa (conserva posiciones).
+func foldText(s string) string {
+	return string(foldRunes([]rune(s)))
+}
+
+func foldRunes(runes []rune) []rune {
+	folded := make([]rune, len(runes))
+	for i, r := range runes {
+		r = unicode.ToLower(r)
+		switch r {
+		case 'á', 'à', 'â', 'ä', 'ã':
+			r = 'a'
+		case 'é', 'è', 'ê', 'ë':
+			r = 'e'
+		case 'í', 'ì', 'î', 'ï':
+			r = 'i'
+		case 'ó', 'ò', 'ô', 'ö', 'õ':
+			r = 'o'
+		case 'ú', 'ù', 'û', 'ü':
+			r = 'u'
+		case 'ñ':
+			r = 'n'
+		case 'ç':
+			r = 'c'
+		}
+		folded[i] = r
+	}
+	return folded
+}
+
+// searchHighlights devuelve, por campo, un fragmento con los términos
+// marcados con <mark>. El texto se escapa para poder mostrarse como HTML.
+func searchHighlights(org *Organization, terms []string) map[string]string {
+	fields := map[string]string{
+		"name":    org.Name,
+		"city":    org.City,
+		"region":  org.Region,
+		"country": org.Country,
+		"tags":    strings.Join(org.Tags, ", "),
+	}
+	if org.Description != nil {
+		fields["description"] = *org.Description
+	}
+	if len(org.Technology) > 0 {
+		fields["technology"] = strings.Join(org.Technology, ", ")
+	}
+
+	highlights := make(map[string]string)
+	for field, text := range fields {
+		if snippet, ok := highlight(text, terms); ok {
+			highlights[field] = snippet
+		}
+	}
+	if len(highlights) == 0 {
+		return nil
+	}
+	return highlights
+}
+
+// highlight marca las palabras de text que empiezan con algún término y
+// recorta alrededor de la primera coincidencia.
+func highlight(text string, terms []string) (string, bool) {
+	runes := []rune(text)
+	folded := foldRunes(runes)
+
+	type span struct{ start, end int }
+	var spans []span
+	for i := 0; i < len(folded); {
+		if !isWordRune(folded[i]) {
+			i++
+			continue
+		}
+		end := i
+		for end < len(folded) && isWordRune(folded[end]) {
+			end++
+		}
+		word := string(folded[i:end])
+		for _, t := range terms {
+			if strings.HasPrefix(word, t) {
+				spans = append(spans, span{i, end})
+				break
+			}
+		}
+		i = end
+	}
+	if len(spans) == 0 {
+		return "", false
+	}
+
+	from := spans[0].start - searchSnippetRadius
+	if from < 0 {
+		from = 0
+	}
+	to := spans[0].end + searchSnippetRadius*2
+	if to > len(runes) {
+		to = len(runes)
+	}
+
+	var sb strings.Builder
+	if from > 0 {
+		sb.WriteString("…")
+	}
+	pos := from
+	for _, s := range spans {
+		if s.start < from || s.end > to {
+			continue
+		}
+		sb.WriteString(html.EscapeString(string(runes[pos:s.start])))
+		sb.WriteString("<mark>")
+		sb.WriteString(html.EscapeString(string(runes[s.start:s.end])))
+		sb.WriteString("</mark>")
+		pos = s.end
+	}
+	sb.WriteString(html.EscapeString(string(runes[pos:to])))
+	if to < len(runes) {
+		sb.WriteString("…")
+	}
+	return sb.String(), true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,137 @@
+package organizations
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"inteligente", "intelig"},
+		{"organizaciones", "organiz"},
+		{"digitalmente", "digital"},
+		{"sensores", "sensor"},
+		{"learning", "learn"},
+		{"cooperativa", "cooperativ"},
+		// La raíz tiene que conservar al menos 4 letras
+		{"casa", "casa"},
+		{"agro", "agro"},
+		{"biotech", "biotech"},
+	}
+	for _, tt := range tests {
+		if got := stem(tt.in); got != tt.want {
+			t.Errorf("stem(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Energía SOLAR", []string{"energi", "solar"}},
+		{"Ñandú", []string{"nandu"}},
+		{"sensores sensor", []string{"sensor"}}, // sin repetidos
+		{"a ab IA 5G abc", []string{"abc"}},     // menos de searchMinTermLen
+		{"O'Brien & Co.", []string{"brien"}},
+		{"", []string{}},
+	}
+	for _, tt := range tests {
+		if got := searchTerms(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("searchTerms(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBooleanQueryStripsOperators(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"agro tech", "agro* tech*"},
+		{`+agro -tech`, "agro* tech*"},
+		{`"robótica" ~drones`, "robotic* dron*"},
+		{`(cafe) <solar> @@`, "cafe* solar*"},
+		{`agro* +(-"~<>@)`, "agro*"},
+		{`+ - < > ( ) ~ * "`, ""},
+	}
+	for _, tt := range tests {
+		got := booleanQuery(searchTerms(tt.in))
+		if got != tt.want {
+			t.Errorf("booleanQuery(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		// Ningún operador del usuario llega a MATCH; solo el * que agregamos al final de cada término
+		for _, term := range strings.Fields(got) {
+			if strings.ContainsAny(strings.TrimSuffix(term, "*"), `+-<>()~*"@`) {
+				t.Errorf("booleanQuery(%q) leaks an operator in %q", tt.in, term)
+			}
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+		found bool
+	}{
+		{
+			name:  "accented words keep their original spelling",
+			text:  "Cooperativa de Energía Solar del Ñandú",
+			terms: []string{"energ", "nandu"},
+			want:  "Cooperativa de <mark>Energía</mark> Solar del <mark>Ñandú</mark>",
+			found: true,
+		},
+		{
+			name:  "offsets after multi-byte runes",
+			text:  "Árbol útil: camión eléctrico",
+			terms: []string{"electr"},
+			want:  "Árbol útil: camión <mark>eléctrico</mark>",
+			found: true,
+		},
+		{
+			name:  "only word prefixes match",
+			text:  "Agroindustria y bioagro",
+			terms: []string{"agro"},
+			want:  "<mark>Agroindustria</mark> y bioagro",
+			found: true,
+		},
+		{
+			name:  "html is escaped",
+			text:  "<b>Agro & Co</b>",
+			terms: []string{"agro"},
+			want:  "&lt;b&gt;<mark>Agro</mark> &amp; Co&lt;/b&gt;",
+			found: true,
+		},
+		{
+			name:  "no match",
+			text:  "Nada que ver",
+			terms: []string{"energ"},
+		},
+	}
+	for _, tt := range tests {
+		got, found := highlight(tt.text, tt.terms)
+		if got != tt.want || found != tt.found {
+			t.Errorf("%s: highlight = %q, %v; want %q, %v", tt.name, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestHighlightTrimsAroundFirstMatch(t *testing.T) {
+	text := strings.Repeat("ñ ", 50) + "energía " + strings.Repeat("á ", 100)
+	got, ok := highlight(text, []string{"energ"})
+	if !ok {
+		t.Fatal("expected a match")
+	}
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("snippet not trimmed: %q", got)
+	}
+	before, after, _ := strings.Cut(got, "<mark>energía</mark>")
+	if n := len([]rune(strings.TrimPrefix(before, "…"))); n != searchSnippetRadius {
+		t.Errorf("context before = %d runes, want %d", n, searchSnippetRadius)
+	}
+	if n := len([]rune(strings.TrimSuffix(after, "…"))); n != searchSnippetRadius*2 {
+		t.Errorf("context after = %d runes, want %d", n, searchSnippetRadius*2)
+	}
+}
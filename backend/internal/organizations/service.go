@@ -2,12 +2,21 @@ package organizations
 
 import (
 	"backend/internal/audit"
+	"backend/internal/lifecycle"
 	"backend/internal/taxonomies"
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"sync"
 	"time"
 )
 
+// auditEntityType identifica a las organizaciones en audit_logs.
+const auditEntityType = "Organization"
+
 type Service struct {
 	repo      *Repository
 	auditRepo *audit.Repository
@@ -16,6 +25,8 @@ type Service struct {
 	taxCache      map[string]map[string]bool
 	taxCacheTime  time.Time
 	taxCacheMutex sync.RWMutex
+
+	publicChangeListeners []func()
 }
 
 func NewService(repo *Repository, auditRepo *audit.Repository, taxRepo taxonomies.Repository) *Service {
@@ -26,17 +37,185 @@ func NewService(repo *Repository, auditRepo *audit.Repository, taxRepo taxonomie
 	}
 }
 
+// OnPublicChange registra fn para que se ejecute cada vez que cambia el
+// conjunto de datos públicos (publicar, archivar o editar una publicada).
+// Se usa para invalidar caches derivados, como los vector tiles.
+func (s *Service) OnPublicChange(fn func()) {
+	s.publicChangeListeners = append(s.publicChangeListeners, fn)
+}
+
+func (s *Service) notifyPublicChange() {
+	for _, fn := range s.publicChangeListeners {
+		fn()
+	}
+}
+
 // Create registra una nueva organización como DRAFT.
-func (s *Service) Create(org *Organization) error {
+func (s *Service) Create(org *Organization, allowDuplicate bool, actor string) error {
 	if err := s.ValidateTaxonomies(org); err != nil {
 		return err
 	}
+	if !allowDuplicate {
+		records, err := s.repo.DuplicateRecordsFor(org)
+		if err != nil {
+			return err
+		}
+		if candidates := findDuplicates(org, records); len(candidates) > 0 {
+			return &DuplicateError{Candidates: candidates}
+		}
+	}
 	org.Status = StatusDraft
-	return s.repo.Create(org)
+	if org.ID == "" {
+		org.ID = newUUIDv7()
+	}
+
+	// El índice único resuelve la carrera entre dos altas con el mismo nombre
+	for attempt := 0; ; attempt++ {
+		if err := s.assignSlug(org, nil); err != nil {
+			return err
+		}
+		err := s.repo.Create(org, actor)
+		if !isSlugConflict(err) || attempt == 2 {
+			return err
+		}
+	}
+}
+
+// assignSlug genera el slug de org a partir del nombre. batch son slugs ya
+// reservados que todavía no están en la base (importación).
+func (s *Service) assignSlug(org *Organization, batch map[string]bool) error {
+	base := slugify(org.Name)
+	taken, err := s.repo.TakenSlugs(base)
+	if err != nil {
+		return err
+	}
+	for slug := range batch {
+		taken[slug] = true
+	}
+	org.Slug = uniqueSlug(base, taken)
+	return nil
+}
+
+// BackfillSlugs asigna slug a las organizaciones creadas antes de que existiera.
+// El slug no cambia después aunque cambie el nombre: es parte de URLs públicas.
+// Corre bajo el lock del scheduler para que dos instancias que arrancan a la
+// vez no le asignen slugs distintos a la misma organización; si otra lo
+// tiene, no hace nada y la próxima que arranque completa lo que falte.
+func (s *Service) BackfillSlugs(ctx context.Context) (int, error) {
+	var n int
+	var err error
+	lockErr := s.repo.WithLock(ctx, schedulerLockName, func() {
+		n, err = s.backfillSlugs()
+	})
+	if lockErr != nil {
+		return 0, lockErr
+	}
+	return n, err
 }
 
-// Update actualiza los datos de la organización.
-func (s *Service) Update(org *Organization) error {
+func (s *Service) backfillSlugs() (int, error) {
+	orgs, err := s.repo.FindWithoutSlug()
+	if err != nil {
+		return 0, err
+	}
+	for i := range orgs {
+		if err := s.assignSlug(&orgs[i], nil); err != nil {
+			return i, err
+		}
+		if err := s.repo.SetSlug(orgs[i].ID, orgs[i].Slug); err != nil {
+			return i, fmt.Errorf("could not set slug for %s: %w", orgs[i].ID, err)
+		}
+	}
+	return len(orgs), nil
+}
+
+// DuplicateClusters agrupa las organizaciones existentes que parecen duplicadas.
+func (s *Service) DuplicateClusters() ([]DuplicateCluster, error) {
+	records, err := s.repo.DuplicateRecords()
+	if err != nil {
+		return nil, err
+	}
+	return clusterDuplicates(records), nil
+}
+
+// Update actualiza los datos de la organización y registra en auditoría
+// cada campo modificado. Si está publicada el cambio no se aplica: queda en
+// su revisión abierta, que se devuelve (nil = se aplicó directamente).
+func (s *Service) Update(org *Organization, ch Change) (*Revision, error) {
+	return s.edit(org, ch, "UPDATE")
+}
+
+// edit aplica org con update o, si la organización está publicada, la propone
+// como revisión.
+func (s *Service) edit(org *Organization, ch Change, action string) (*Revision, error) {
+	existing, err := s.repo.FindByID(org.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Status != StatusPublished {
+		return nil, s.update(org, ch, action)
+	}
+	return s.propose(existing, org, ch)
+}
+
+// propose guarda org como la versión propuesta de la revisión abierta de
+// existing (creándola si no hay). La fila publicada no cambia.
+func (s *Service) propose(existing, org *Organization, ch Change) (*Revision, error) {
+	if err := s.ValidateTaxonomies(org); err != nil {
+		return nil, err
+	}
+	if err := ch.checkVersion(existing); err != nil {
+		return nil, err
+	}
+
+	// El índice de revisión abierta resuelve la carrera entre dos ediciones
+	// que la crean a la vez: la que pierde se suma a la de la otra
+	for attempt := 0; ; attempt++ {
+		rev, err := s.saveProposal(existing, org, ch)
+		if !isOpenRevisionConflict(err) || attempt == 1 {
+			return rev, err
+		}
+	}
+}
+
+// saveProposal crea o actualiza la revisión abierta con org.
+func (s *Service) saveProposal(existing, org *Organization, ch Change) (*Revision, error) {
+	rev, err := s.repo.FindOpenRevision(existing.ID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		rev = &Revision{OrganizationID: existing.ID, BaseVersion: existing.Version, Status: RevisionDraft, CreatedBy: ch.Actor, Base: existing, Proposed: existing}
+	case err != nil:
+		return nil, err
+	case rev.Status == RevisionInReview:
+		return nil, ErrRevisionInReview
+	}
+
+	proposed := restoredFrom(existing, org)
+	changes := diffOrganizations(rev.Proposed, proposed)
+	if len(changes) == 0 {
+		if rev.ID == 0 {
+			*org = *existing
+			return nil, nil
+		}
+		return rev, nil
+	}
+
+	rev.Proposed = proposed
+	if err := s.repo.SaveRevision(rev); err != nil {
+		return nil, err
+	}
+	rev.Changes = diffOrganizations(rev.Base, rev.Proposed)
+	s.logFieldChanges(existing.ID, "PROPOSE_REVISION", ch.Actor, changes)
+
+	// Releemos para devolver timestamps reales
+	if saved, err := s.repo.FindRevision(existing.ID, rev.ID); err == nil {
+		rev = saved
+	}
+	return rev, nil
+}
+
+// update persiste los campos editables de org; action es la acción de auditoría.
+func (s *Service) update(org *Organization, ch Change, action string) error {
 	if err := s.ValidateTaxonomies(org); err != nil {
 		return err
 	}
@@ -45,28 +224,440 @@ func (s *Service) Update(org *Organization) error {
 	if err != nil {
 		return err
 	}
+	if err := ch.checkVersion(existing); err != nil {
+		return err
+	}
 	org.Status = existing.Status
-	return s.repo.Update(org)
+	org.Version = existing.Version
+
+	changes := diffOrganizations(existing, org)
+	if len(changes) == 0 {
+		*org = *existing
+		return nil
+	}
+
+	if err := s.repo.UpdateFields(org, changes, ch.Actor); err != nil {
+		return err
+	}
+	s.logFieldChanges(org.ID, action, ch.Actor, changes)
+	if existing.Status == StatusPublished {
+		s.notifyPublicChange()
+	}
+
+	// Devolvemos el registro tal como quedó (timestamps y versión incluidos)
+	if updated, err := s.repo.FindByID(org.ID); err == nil {
+		*org = *updated
+	}
+	return nil
+}
+
+// Patch aplica un JSON Merge Patch (RFC 7396) sobre la organización guardada,
+// normaliza y valida el resultado y persiste solo los campos que cambian.
+// Si está publicada el patch se acumula sobre su revisión abierta, que se devuelve.
+func (s *Service) Patch(id string, patch map[string]any, ch Change) (*Organization, *Revision, error) {
+	existing, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ch.checkVersion(existing); err != nil {
+		return nil, nil, err
+	}
+
+	base := existing
+	if existing.Status == StatusPublished {
+		rev, err := s.repo.FindOpenRevision(id)
+		if err == nil {
+			base = rev.Proposed
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, err
+		}
+	}
+
+	org, err := mergeOrganization(base, patch)
+	if err != nil {
+		return nil, nil, invalid(err)
+	}
+	if err := Normalize(org); err != nil {
+		return nil, nil, invalid(err)
+	}
+
+	// El merge parte de esta versión: si otro cambio se adelanta, fallamos en vez de pisarlo
+	ch.IfMatch = existing.Version
+	rev, err := s.Update(org, ch)
+	if err != nil || rev != nil {
+		return nil, rev, err
+	}
+	return org, nil, nil
+}
+
+// Merge fusiona req.SourceID en targetID: aplica la elección de campos, une
+// los multivaluados, borra el origen y deja su id como alias del destino.
+// ch.IfMatch se compara contra la versión del destino. Un origen publicado se
+// borra igual (sus links redirigen al destino); si el destino está publicado,
+// los campos fusionados quedan en su revisión abierta, que se devuelve.
+func (s *Service) Merge(targetID string, req MergeRequest, ch Change) (*Organization, *Revision, error) {
+	if req.SourceID == "" {
+		return nil, nil, invalid(fmt.Errorf("sourceId is required"))
+	}
+	if req.SourceID == targetID {
+		return nil, nil, invalid(fmt.Errorf("cannot merge an organization into itself"))
+	}
+
+	target, err := s.repo.FindByID(targetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ch.checkVersion(target); err != nil {
+		return nil, nil, err
+	}
+	source, err := s.repo.FindByID(req.SourceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("source organization %s: %w", req.SourceID, err)
+	}
+
+	// Publicado, el destino se fusiona sobre lo que ya propone su revisión abierta
+	base := target
+	if target.Status == StatusPublished {
+		rev, err := s.repo.FindOpenRevision(target.ID)
+		switch {
+		case err == nil && rev.Status == RevisionInReview:
+			// Se chequea antes de borrar el origen, para no perder sus datos
+			return nil, nil, ErrRevisionInReview
+		case err == nil:
+			base = restoredFrom(target, rev.Proposed)
+		case !errors.Is(err, sql.ErrNoRows):
+			return nil, nil, err
+		}
+	}
+
+	merged, err := mergeRecords(base, source, req.Fields)
+	if err != nil {
+		return nil, nil, invalid(err)
+	}
+	if err := Normalize(merged); err != nil {
+		return nil, nil, invalid(err)
+	}
+	if err := s.ValidateTaxonomies(merged); err != nil {
+		return nil, nil, err
+	}
+
+	var changes []FieldChange
+	if target.Status != StatusPublished {
+		changes = diffOrganizations(target, merged)
+	}
+	if err := s.repo.Merge(merged, changes, source, ch.Actor); err != nil {
+		return nil, nil, err
+	}
+
+	reason := func(base string) string {
+		if ch.Reason != "" {
+			return base + ": " + ch.Reason
+		}
+		return base
+	}
+	s.logFieldChanges(target.ID, "MERGE", ch.Actor, changes)
+	events := []*audit.AuditLog{
+		{
+			EntityType:  auditEntityType,
+			EntityID:    target.ID,
+			Action:      "MERGE",
+			Reason:      reason("absorbed " + source.ID),
+			PerformedBy: ch.Actor,
+		},
+		{
+			EntityType:  auditEntityType,
+			EntityID:    source.ID,
+			Action:      "MERGE",
+			FromStatus:  string(source.Status),
+			Reason:      reason("merged into " + target.ID),
+			PerformedBy: ch.Actor,
+		},
+	}
+	if err := s.auditRepo.LogAll(events); err != nil {
+		log.Printf("audit: could not record merge of %s into %s: %v", source.ID, target.ID, err)
+	}
+	if source.Status == StatusPublished || target.Status == StatusPublished {
+		s.notifyPublicChange()
+	}
+
+	updated, err := s.repo.FindByID(target.ID)
+	if err != nil || target.Status != StatusPublished {
+		return updated, nil, err
+	}
+	ch.IfMatch = updated.Version
+	rev, err := s.propose(updated, merged, ch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("merged %s, but could not save the revision: %w", source.ID, err)
+	}
+	return updated, rev, nil
+}
+
+// Versions lista los snapshots guardados de una organización.
+func (s *Service) Versions(id string) ([]OrganizationVersion, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindVersions(id)
+}
+
+// Version devuelve un snapshot concreto.
+func (s *Service) Version(id string, version int) (*OrganizationVersion, error) {
+	return s.repo.FindVersion(id, version)
+}
+
+// DiffVersions compara dos snapshots; to = 0 compara contra el estado actual.
+func (s *Service) DiffVersions(id string, from, to int) (*VersionDiff, error) {
+	a, err := s.repo.FindVersion(id, from)
+	if err != nil {
+		return nil, fmt.Errorf("version %d: %w", from, err)
+	}
+
+	var b *Organization
+	if to == 0 {
+		if b, err = s.repo.FindByID(id); err != nil {
+			return nil, err
+		}
+		to = b.Version
+	} else {
+		v, err := s.repo.FindVersion(id, to)
+		if err != nil {
+			return nil, fmt.Errorf("version %d: %w", to, err)
+		}
+		b = v.Snapshot
+	}
+
+	changes := diffOrganizations(a.Snapshot, b)
+	if changes == nil {
+		changes = make([]FieldChange, 0)
+	}
+	return &VersionDiff{From: from, To: to, Changes: changes}, nil
+}
+
+// RestoreVersion vuelve los campos editables al snapshot indicado como una
+// edición más (nueva versión, auditada como RESTORE_VERSION). El status no
+// cambia: eso lo decide la máquina de estados. Si está publicada, como
+// cualquier edición, queda en su revisión abierta.
+func (s *Service) RestoreVersion(id string, version int, ch Change) (*Organization, *Revision, error) {
+	snap, err := s.repo.FindVersion(id, version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("version %d: %w", version, err)
+	}
+	current, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ch.checkVersion(current); err != nil {
+		return nil, nil, err
+	}
+
+	org := restoredFrom(current, snap.Snapshot)
+	ch.IfMatch = current.Version
+	rev, err := s.edit(org, ch, "RESTORE_VERSION")
+	if err != nil || rev != nil {
+		return nil, rev, err
+	}
+	return org, nil, nil
+}
+
+// Revisions lista las revisiones de una organización (id vacío = de todas),
+// sin el snapshot propuesto.
+func (s *Service) Revisions(id string, status RevisionStatus) ([]Revision, error) {
+	revisions, err := s.repo.FindRevisions(id, status)
+	if err != nil {
+		return nil, err
+	}
+	for i := range revisions {
+		revisions[i].Proposed = nil
+	}
+	return revisions, nil
+}
+
+// Revision devuelve una revisión con su snapshot propuesto.
+func (s *Service) Revision(id string, revisionID int64) (*Revision, error) {
+	return s.repo.FindRevision(id, revisionID)
+}
+
+// DiffRevision compara lo publicado hoy con lo que quedaría al aprobar la
+// revisión. Una revisión cerrada se compara contra su base.
+func (s *Service) DiffRevision(id string, revisionID int64) (*VersionDiff, error) {
+	rev, err := s.repo.FindRevision(id, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if !rev.isOpen() {
+		return &VersionDiff{From: rev.BaseVersion, Changes: rev.Changes}, nil
+	}
+
+	current, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := rev.appliedTo(current)
+	if err != nil {
+		return nil, err
+	}
+	return &VersionDiff{From: current.Version, Changes: diffOrganizations(current, applied)}, nil
+}
+
+// SubmitRevision envía a revisión una revisión en DRAFT; desde ahí no acepta más cambios.
+func (s *Service) SubmitRevision(id string, revisionID int64, ch Change) (*Revision, error) {
+	return s.moveRevision(id, revisionID, RevisionInReview, "SUBMIT_REVISION", ch)
+}
+
+// DiscardRevision cierra una revisión abierta sin aplicarla.
+func (s *Service) DiscardRevision(id string, revisionID int64, ch Change) (*Revision, error) {
+	return s.moveRevision(id, revisionID, RevisionDiscarded, "DISCARD_REVISION", ch)
+}
+
+func (s *Service) moveRevision(id string, revisionID int64, to RevisionStatus, action string, ch Change) (*Revision, error) {
+	rev, err := s.repo.FindRevision(id, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if to == RevisionInReview && rev.Status != RevisionDraft {
+		return nil, invalid(fmt.Errorf("can only submit a revision in %s", RevisionDraft))
+	}
+	if !rev.isOpen() {
+		return nil, invalid(fmt.Errorf("revision %d is already %s", rev.ID, rev.Status))
+	}
+
+	if err := s.repo.UpdateRevisionStatus(rev.ID, rev.Status, to, ch.Actor); err != nil {
+		return nil, err
+	}
+	s.logAudit(&audit.AuditLog{
+		EntityID:    id,
+		EntityType:  auditEntityType,
+		Action:      action,
+		Reason:      revisionReason(rev.ID, ch.Reason),
+		PerformedBy: ch.Actor,
+	})
+	return s.repo.FindRevision(id, rev.ID)
+}
+
+// ApproveRevision publica una revisión IN_REVIEW: aplica sobre la
+// organización los campos que la revisión cambió respecto de su base.
+// ch.IfMatch se compara contra la versión de la organización.
+func (s *Service) ApproveRevision(id string, revisionID int64, ch Change) (*Organization, error) {
+	rev, err := s.repo.FindRevision(id, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if rev.Status != RevisionInReview {
+		return nil, invalid(fmt.Errorf("can only approve a revision in %s", RevisionInReview))
+	}
+	current, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status != StatusPublished {
+		return nil, invalid(fmt.Errorf("organization is %s; only revisions of PUBLISHED organizations can be approved", current.Status))
+	}
+	if err := ch.checkVersion(current); err != nil {
+		return nil, err
+	}
+
+	org, err := rev.appliedTo(current)
+	if err != nil {
+		return nil, err
+	}
+	if err := Normalize(org); err != nil {
+		return nil, invalid(err)
+	}
+	if err := s.ValidateTaxonomies(org); err != nil {
+		return nil, err
+	}
+	if err := ValidateForPublish(org); err != nil {
+		return nil, invalid(fmt.Errorf("publish validation failed: %w", err))
+	}
+
+	changes := diffOrganizations(current, org)
+	if err := s.repo.ApproveRevision(org, changes, rev, ch.Actor); err != nil {
+		return nil, err
+	}
+	s.logFieldChanges(id, "APPROVE_REVISION", ch.Actor, changes)
+	s.logAudit(&audit.AuditLog{
+		EntityID:    id,
+		EntityType:  auditEntityType,
+		Action:      "APPROVE_REVISION",
+		Reason:      revisionReason(rev.ID, ch.Reason),
+		PerformedBy: ch.Actor,
+	})
+	if len(changes) > 0 {
+		s.notifyPublicChange()
+	}
+	return s.repo.FindByID(id)
+}
+
+func revisionReason(id int64, reason string) string {
+	base := fmt.Sprintf("revision %d", id)
+	if reason != "" {
+		return base + ": " + reason
+	}
+	return base
+}
+
+// UpdateCoordinates fija lat/lng (manual o por geocoding) y audita el cambio.
+// action distingue el origen: "UPDATE_COORDINATES" o "GEOCODE". Como toda
+// edición, sobre una organización publicada queda en su revisión abierta.
+func (s *Service) UpdateCoordinates(id string, lat, lng float64, action string, ch Change) (*Organization, *Revision, error) {
+	existing, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ch.checkVersion(existing); err != nil {
+		return nil, nil, err
+	}
+
+	updated := *existing
+	updated.Lat = &lat
+	updated.Lng = &lng
+
+	ch.IfMatch = existing.Version
+	rev, err := s.edit(&updated, ch, action)
+	if err != nil || rev != nil {
+		return nil, rev, err
+	}
+	return &updated, nil, nil
+}
+
+// logFieldChanges escribe un evento de auditoría por campo modificado.
+// Un fallo de auditoría no revierte el cambio ya persistido; se reporta en el log.
+func (s *Service) logFieldChanges(id, action, actor string, changes []FieldChange) {
+	events := make([]*audit.AuditLog, 0, len(changes))
+	for _, c := range changes {
+		events = append(events, &audit.AuditLog{
+			EntityType:  auditEntityType,
+			EntityID:    id,
+			Action:      action,
+			FieldName:   c.Field,
+			OldValue:    c.OldValue,
+			NewValue:    c.NewValue,
+			PerformedBy: actor,
+		})
+	}
+	if err := s.auditRepo.LogAll(events); err != nil {
+		log.Printf("audit: could not record changes for %s: %v", id, err)
+	}
 }
 
 // Delete elimina o archiva una organización según su estado.
-func (s *Service) Delete(id string, force bool) error {
+func (s *Service) Delete(id string, force bool, ch Change) error {
 	org, err := s.repo.FindByID(id)
 	if err != nil {
 		return err
 	}
+	if err := ch.checkVersion(org); err != nil {
+		return err
+	}
 
 	if org.Status == StatusPublished {
 		// Si está publicado, no borramos físico, archivamos.
-		_ = s.auditRepo.Log(&audit.AuditLog{
-			EntityID:    id,
-			EntityType:  "Organization",
-			Action:      "ARCHIVE",
-			FromStatus:  "PUBLISHED",
-			ToStatus:    "ARCHIVED",
-			PerformedBy: "system/delete-request",
-		})
-		_ = s.repo.UpdateStatus(id, StatusArchived)
+		if ch.Reason == "" {
+			ch.Reason = "delete request"
+		}
+		if err := s.transition(id, StatusArchived, ch, nil); err != nil {
+			return err
+		}
 		return fmt.Errorf("published organizations cannot be hard deleted; status has been set to ARCHIVED instead")
 	}
 
@@ -75,78 +666,205 @@ func (s *Service) Delete(id string, force bool) error {
 	}
 
 	// DRAFT o IN_REVIEW (o ARCHIVED con force) -> Hard delete
-	_ = s.auditRepo.Log(&audit.AuditLog{
+	if err := s.repo.Delete(id, org.Version, ch.Actor); err != nil {
+		return err
+	}
+	s.logAudit(&audit.AuditLog{
 		EntityID:    id,
-		EntityType:  "Organization",
+		EntityType:  auditEntityType,
 		Action:      "DELETE",
 		FromStatus:  string(org.Status),
 		ToStatus:    "DELETED",
-		PerformedBy: "admin",
+		Reason:      ch.Reason,
+		PerformedBy: ch.Actor,
 	})
-	return s.repo.Delete(id)
+	return nil
 }
 
-// SubmitForReview mueve a IN_REVIEW. Permite retroceder de PUBLISHED o volver de DRAFT.
-func (s *Service) SubmitForReview(id string) error {
-	org, err := s.repo.FindByID(id)
-	if err != nil {
-		return err
-	}
+// SubmitForReview mueve a IN_REVIEW.
+func (s *Service) SubmitForReview(id string, ch Change) error {
+	return s.transition(id, StatusInReview, ch, nil)
+}
 
-	// Transición válida: DRAFT -> IN_REVIEW o PUBLISHED -> IN_REVIEW (re-evaluación)
-	if org.Status != StatusDraft && org.Status != StatusPublished && org.Status != StatusArchived {
-		return fmt.Errorf("invalid transition to IN_REVIEW from %s", org.Status)
-	}
+// Publish realiza el checklist del Word antes de publicar.
+func (s *Service) Publish(id string, ch Change) error {
+	return s.transition(id, StatusPublished, ch, func(org *Organization) error {
+		if err := ValidateForPublish(org); err != nil {
+			return fmt.Errorf("publish validation failed: %w", err)
+		}
+		return nil
+	})
+}
 
-	return s.repo.UpdateStatus(id, StatusInReview)
+// Archive retira una organización publicada.
+func (s *Service) Archive(id string, ch Change) error {
+	return s.transition(id, StatusArchived, ch, nil)
 }
 
-// Publish realiza el checklist del Word antes de publicar.
-func (s *Service) Publish(id string) error {
-	org, err := s.repo.FindByID(id)
-	if err != nil {
-		return err
+// Reject devuelve a DRAFT desde IN_REVIEW para correcciones. El motivo es obligatorio.
+func (s *Service) Reject(id string, ch Change) error {
+	ch.Reason = strings.TrimSpace(ch.Reason)
+	if ch.Reason == "" {
+		return fmt.Errorf("reason is required to reject")
 	}
+	return s.transition(id, StatusDraft, ch, func(org *Organization) error {
+		if org.Status != StatusInReview {
+			return fmt.Errorf("can only reject from IN_REVIEW")
+		}
+		return nil
+	})
+}
+
+// Restore devuelve a DRAFT una organización archivada para corregirla.
+func (s *Service) Restore(id string, ch Change) error {
+	ch.Reason = strings.TrimSpace(ch.Reason)
+	return s.transition(id, StatusDraft, ch, func(org *Organization) error {
+		if org.Status != StatusArchived {
+			return fmt.Errorf("can only restore from ARCHIVED")
+		}
+		return nil
+	})
+}
 
-	// Solo se puede publicar desde IN_REVIEW (proceso formal)
-	if org.Status != StatusInReview {
-		return fmt.Errorf("organization must be in IN_REVIEW status to be published (current: %s)", org.Status)
+// RunScheduler ejecuta cada interval las publicaciones y archivados
+// programados, hasta que ctx se cancele. Con varias instancias cada ronda la
+// corre una sola (GET_LOCK); igual, cada transición lleva la versión leída
+// como If-Match, así que una carrera nunca la aplica dos veces.
+func (s *Service) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := s.repo.WithLock(ctx, schedulerLockName, func() {
+			due, err := s.repo.FindDueSchedules(scheduleBatchSize)
+			if err != nil {
+				log.Printf("scheduler: %v", err)
+				return
+			}
+			for _, t := range due {
+				if ctx.Err() != nil {
+					return
+				}
+				s.runScheduled(t)
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			log.Printf("scheduler: could not take lock: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
+}
 
-	// Aplicar checklist del Word
-	if err := ValidateForPublish(org); err != nil {
-		return fmt.Errorf("publish validation failed: %w", err)
+// runScheduled publica (con el checklist de Publish) o archiva una
+// organización. Si falla, el motivo queda en la organización y en auditoría.
+func (s *Service) runScheduled(t ScheduledTransition) {
+	field := "publishAt"
+	if t.To == StatusArchived {
+		field = "unpublishAt"
+	}
+	ch := Change{
+		Actor:   schedulerActor,
+		IfMatch: t.Version,
+		Reason:  fmt.Sprintf("scheduled %s %s", field, t.At.Format(time.RFC3339)),
 	}
 
-	return s.repo.UpdateStatus(id, StatusPublished)
+	var err error
+	if t.To == StatusPublished {
+		err = s.Publish(t.ID, ch)
+	} else {
+		err = s.Archive(t.ID, ch)
+	}
+	switch {
+	case err == nil:
+		log.Printf("scheduler: %s %s -> %s", t.ID, t.From, t.To)
+	case errors.Is(err, ErrPreconditionFailed):
+		// Alguien la modificó después de leerla; la próxima ronda la vuelve a evaluar
+	default:
+		log.Printf("scheduler: %s -> %s failed: %v", t.ID, t.To, err)
+		if rerr := s.repo.RecordScheduleFailure(t.ID, err.Error()); rerr != nil {
+			log.Printf("scheduler: could not record failure for %s: %v", t.ID, rerr)
+		}
+		s.logAudit(&audit.AuditLog{
+			EntityID:    t.ID,
+			EntityType:  auditEntityType,
+			Action:      "SCHEDULE_FAILED",
+			FromStatus:  string(t.From),
+			ToStatus:    string(t.To),
+			Reason:      ch.Reason + ": " + err.Error(),
+			PerformedBy: schedulerActor,
+		})
+	}
 }
 
-// Archive mueve a ARCHIVED desde cualquier estado excepto si ya está archivado.
-func (s *Service) Archive(id string) error {
+// transition es el único punto donde cambia el status de una organización:
+// valida contra lifecycle.AllowedTransitions, ejecuta el chequeo opcional,
+// persiste el nuevo estado y lo registra en auditoría.
+func (s *Service) transition(id string, to OrganizationStatus, ch Change, check func(org *Organization) error) error {
 	org, err := s.repo.FindByID(id)
 	if err != nil {
 		return err
 	}
+	if err := ch.checkVersion(org); err != nil {
+		return err
+	}
 
-	if org.Status == StatusArchived {
-		return nil
+	from := org.Status
+	if err := lifecycle.ValidateTransition(string(from), string(to)); err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(org); err != nil {
+			return err
+		}
 	}
 
-	return s.repo.UpdateStatus(id, StatusArchived)
-}
+	// Al dejar PUBLISHED, UpdateStatus descarta la revisión abierta
+	var open *Revision
+	if from == StatusPublished && to != StatusPublished {
+		if open, err = s.repo.FindOpenRevision(id); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
 
-// Reject devuelve a DRAFT desde IN_REVIEW para correcciones.
-func (s *Service) Reject(id string) error {
-	org, err := s.repo.FindByID(id)
-	if err != nil {
+	if err := s.repo.UpdateStatus(id, to, org.Version, ch.Actor); err != nil {
 		return err
 	}
 
-	if org.Status != StatusInReview {
-		return fmt.Errorf("can only reject from IN_REVIEW")
+	t, _ := lifecycle.Find(string(from), string(to))
+	s.logAudit(&audit.AuditLog{
+		EntityID:    id,
+		EntityType:  auditEntityType,
+		Action:      t.Action,
+		FromStatus:  string(from),
+		ToStatus:    string(to),
+		Reason:      ch.Reason,
+		PerformedBy: ch.Actor,
+	})
+	// UpdateStatus consume la fecha programada que corresponde a la transición
+	s.logFieldChanges(id, t.Action, ch.Actor, diffOrganizations(org, consumeSchedule(org, to)))
+	if open != nil {
+		s.logAudit(&audit.AuditLog{
+			EntityID:    id,
+			EntityType:  auditEntityType,
+			Action:      "DISCARD_REVISION",
+			Reason:      revisionReason(open.ID, "organization left PUBLISHED"),
+			PerformedBy: ch.Actor,
+		})
 	}
+	if from == StatusPublished || to == StatusPublished {
+		s.notifyPublicChange()
+	}
+	return nil
+}
 
-	return s.repo.UpdateStatus(id, StatusDraft)
+func (s *Service) logAudit(event *audit.AuditLog) {
+	if err := s.auditRepo.Log(event); err != nil {
+		log.Printf("audit: could not record %s for %s: %v", event.Action, event.EntityID, err)
+	}
 }
 
 // ValidateTaxonomies verifica que los campos seleccionados existan en las listas controladas.
@@ -185,16 +903,16 @@ func (s *Service) ValidateTaxonomies(org *Organization) error {
 
 	// Validar campos simples
 	if org.OrganizationType != "" && !grouped["organizationType"][org.OrganizationType] {
-		return fmt.Errorf("invalid organizationType: %s", org.OrganizationType)
+		return invalid(fmt.Errorf("invalid organizationType: %s", org.OrganizationType))
 	}
 	if org.SectorPrimary != "" && !grouped["sectorPrimary"][org.SectorPrimary] {
-		return fmt.Errorf("invalid sectorPrimary: %s", org.SectorPrimary)
+		return invalid(fmt.Errorf("invalid sectorPrimary: %s", org.SectorPrimary))
 	}
 	if org.Stage != nil && *org.Stage != "" && !grouped["stage"][*org.Stage] {
-		return fmt.Errorf("invalid stage: %s", *org.Stage)
+		return invalid(fmt.Errorf("invalid stage: %s", *org.Stage))
 	}
 	if org.OutcomeStatus != "" && !grouped["outcomeStatus"][org.OutcomeStatus] {
-		return fmt.Errorf("invalid outcomeStatus: %s", org.OutcomeStatus)
+		return invalid(fmt.Errorf("invalid outcomeStatus: %s", org.OutcomeStatus))
 	}
 
 	// Validar campos multi-selección
@@ -218,7 +936,7 @@ func (s *Service) checkMulti(values []string, validMap map[string]bool, fieldNam
 	}
 	for _, v := range values {
 		if !validMap[v] {
-			return fmt.Errorf("invalid %s: %s", fieldName, v)
+			return invalid(fmt.Errorf("invalid %s: %s", fieldName, v))
 		}
 	}
 	return nil
@@ -0,0 +1,113 @@
+package organizations
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Métricas de /public/stats/timeseries.
+const (
+	metricFounded   = "founded"   // por año de year_founded
+	metricPublished = "published" // por mes de la primera publicación
+)
+
+// TimeseriesPoint es el conteo de un período (año "2021" o mes "2021-03").
+// Cumulative acumula los períodos anteriores de la misma serie.
+type TimeseriesPoint struct {
+	Period     string `json:"period"`
+	Count      int    `json:"count"`
+	Cumulative int    `json:"cumulative"`
+}
+
+// TimeseriesSeries es la serie de un valor de la dimensión agrupada
+// (Group vacío si no se agrupa).
+type TimeseriesSeries struct {
+	Group  string            `json:"group"`
+	Points []TimeseriesPoint `json:"points"`
+}
+
+// TimeseriesResponse es la respuesta JSON de /public/stats/timeseries.
+type TimeseriesResponse struct {
+	Metric   string             `json:"metric"`
+	Interval string             `json:"interval"`
+	GroupBy  string             `json:"groupBy,omitempty"`
+	Series   []TimeseriesSeries `json:"series"`
+}
+
+// TimeseriesQuery son los parámetros ya validados de una serie temporal.
+type TimeseriesQuery struct {
+	Metric  string
+	GroupBy string // parámetro de la API ("country"); "" = sin agrupar
+	column  string
+}
+
+// parseTimeseriesQuery valida ?metric= y ?groupBy= (cualquier dimensión de
+// scalarFilters salvo status).
+func parseTimeseriesQuery(params map[string]string) (TimeseriesQuery, error) {
+	q := TimeseriesQuery{Metric: params["metric"], GroupBy: params["groupBy"]}
+	if q.Metric == "" {
+		q.Metric = metricFounded
+	}
+	if q.Metric != metricFounded && q.Metric != metricPublished {
+		return q, fmt.Errorf("metric must be %s or %s", metricFounded, metricPublished)
+	}
+	if q.GroupBy == "" {
+		return q, nil
+	}
+	for _, f := range scalarFilters {
+		if f.Param == q.GroupBy && f.Param != "status" {
+			q.column = f.Column
+			return q, nil
+		}
+	}
+	return q, fmt.Errorf("cannot group by %q", q.GroupBy)
+}
+
+// interval es la granularidad de la métrica.
+func (q TimeseriesQuery) interval() string {
+	if q.Metric == metricPublished {
+		return "month"
+	}
+	return "year"
+}
+
+// timeseriesRow es una fila de la consulta agrupada.
+type timeseriesRow struct {
+	Group  string
+	Period string
+	Count  int
+}
+
+// buildSeries arma las series a partir de filas ordenadas por grupo y período.
+func buildSeries(q TimeseriesQuery, rows []timeseriesRow) *TimeseriesResponse {
+	resp := &TimeseriesResponse{Metric: q.Metric, Interval: q.interval(), GroupBy: q.GroupBy, Series: make([]TimeseriesSeries, 0)}
+	for _, row := range rows {
+		n := len(resp.Series)
+		if n == 0 || resp.Series[n-1].Group != row.Group {
+			resp.Series = append(resp.Series, TimeseriesSeries{Group: row.Group, Points: make([]TimeseriesPoint, 0)})
+			n++
+		}
+		s := &resp.Series[n-1]
+		cumulative := row.Count
+		if len(s.Points) > 0 {
+			cumulative += s.Points[len(s.Points)-1].Cumulative
+		}
+		s.Points = append(s.Points, TimeseriesPoint{Period: row.Period, Count: row.Count, Cumulative: cumulative})
+	}
+	return resp
+}
+
+// writeTimeseriesCSV escribe una fila por grupo y período.
+func writeTimeseriesCSV(w io.Writer, resp *TimeseriesResponse) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"group", "period", "count", "cumulative"})
+	for _, s := range resp.Series {
+		for _, p := range s.Points {
+			cw.Write([]string{csvSafe(s.Group), p.Period, strconv.Itoa(p.Count), strconv.Itoa(p.Cumulative)})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,32 @@
+package organizations
+
+import "time"
+
+// OrganizationVersion es un snapshot completo de la organización tal como
+// quedó guardada en esa versión. Snapshot se omite en el listado.
+type OrganizationVersion struct {
+	Version   int           `json:"version"`
+	CreatedBy string        `json:"createdBy,omitempty"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Snapshot  *Organization `json:"snapshot,omitempty"`
+}
+
+// VersionDiff son los campos editables que cambian de From a To.
+type VersionDiff struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// restoredFrom arma la organización a guardar al restaurar snapshot sobre
+// current: vuelven los campos editables; id, slug, status y versión son los actuales.
+func restoredFrom(current, snapshot *Organization) *Organization {
+	restored := *snapshot
+	restored.ID = current.ID
+	restored.Slug = current.Slug
+	restored.Status = current.Status
+	restored.Version = current.Version
+	restored.CreatedAt = current.CreatedAt
+	restored.UpdatedAt = current.UpdatedAt
+	return &restored
+}
@@ -0,0 +1,241 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Read detecta el formato (XLSX si es un zip, CSV en otro caso) y devuelve
+// las filas de la primera hoja como texto.
+func Read(data []byte) ([][]string, error) {
+	if IsXLSX(data) {
+		return ReadXLSX(data)
+	}
+	return ReadCSV(data)
+}
+
+// IsXLSX informa si data parece un archivo XLSX (zip).
+func IsXLSX(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("PK\x03\x04"))
+}
+
+// ReadCSV lee un CSV separado por coma o punto y coma (Excel en español
+// exporta con ';'). Se detecta el separador a partir de la primera línea.
+func ReadCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM UTF-8
+
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	return rows, nil
+}
+
+// --- XLSX ---
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+// xlsxRichText cubre tanto <t> simple como texto con formato (<r><t>).
+type xlsxRichText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (rt xlsxRichText) String() string {
+	if len(rt.Runs) == 0 {
+		return rt.T
+	}
+	var sb strings.Builder
+	for _, r := range rt.Runs {
+		sb.WriteString(r.T)
+	}
+	return sb.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Ref   int `xml:"r,attr"` // número de fila, desde 1; 0 si falta
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// Límites de una hoja de Excel; una referencia mayor es un archivo roto.
+const (
+	maxRows    = 1048576
+	maxColumns = 16384
+)
+
+// ReadXLSX lee la primera hoja de un libro XLSX.
+func ReadXLSX(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXMLFile(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("invalid XLSX: missing %s", sheetPath)
+	}
+	var sheet xlsxSheet
+	if err := decodeXMLFile(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	// Excel omite las filas y celdas vacías: r ubica cada una en su lugar.
+	// Sin r, van a continuación de la anterior.
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		if row.Ref > 0 {
+			if row.Ref > maxRows || row.Ref <= len(rows) {
+				return nil, fmt.Errorf("invalid XLSX: bad row number %d", row.Ref)
+			}
+			for len(rows) < row.Ref-1 {
+				rows = append(rows, []string{})
+			}
+		}
+
+		values := make([]string, 0, len(row.Cells))
+		for _, c := range row.Cells {
+			col := len(values)
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+				if col < len(values) || col >= maxColumns {
+					return nil, fmt.Errorf("invalid XLSX: bad cell reference %q", c.Ref)
+				}
+			}
+			for len(values) < col {
+				values = append(values, "")
+			}
+
+			var v string
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(c.Value)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fmt.Errorf("invalid XLSX: bad shared string in %s", c.Ref)
+				}
+				v = shared.Items[idx].String()
+			case "inlineStr":
+				v = c.Inline.String()
+			default:
+				v = c.Value
+			}
+			values = append(values, v)
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	wbFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", fmt.Errorf("invalid XLSX: missing workbook")
+	}
+	var wb xlsxWorkbook
+	if err := decodeXMLFile(wbFile, &wb); err != nil {
+		return "", err
+	}
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if len(wb.Sheets) == 0 || !ok {
+		return fallback, nil
+	}
+	var rels xlsxRelationships
+	if err := decodeXMLFile(relsFile, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID == wb.Sheets[0].RID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+	return fallback, nil
+}
+
+func decodeXMLFile(f *zip.File, dst any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, 256<<20)).Decode(dst); err != nil {
+		return fmt.Errorf("invalid XLSX (%s): %w", f.Name, err)
+	}
+	return nil
+}
+
+// columnIndex convierte una referencia de celda ("C12") en índice de columna (2).
+func columnIndex(ref string) int {
+	idx := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		idx = idx*26 + int(ch-'A'+1)
+	}
+	return idx - 1
+}
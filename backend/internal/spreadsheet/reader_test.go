@@ -0,0 +1,122 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// buildXLSX arma un libro mínimo con sheetData como contenido de la hoja.
+func buildXLSX(t *testing.T, sheetData string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheets/></workbook>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>name</t></si><si><r><t>Ac</t></r><r><t>me</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			sheetData + `</sheetData></worksheet>`,
+	}
+	for name, body := range parts {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadXLSXHonorsReferences(t *testing.T) {
+	data := buildXLSX(t,
+		`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="inlineStr"><is><t>country</t></is></c></row>`+
+			`<row r="4"><c r="B4" t="s"><v>1</v></c><c r="C4"><v>42</v></c></row>`+
+			`<row><c><v>x</v></c><c><v>y</v></c></row>`)
+
+	rows, err := ReadXLSX(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"name", "", "country"},
+		{},
+		{},
+		{"", "Acme", "42"},
+		{"x", "y"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
+
+func TestReadXLSXRejectsBadReferences(t *testing.T) {
+	tests := map[string]string{
+		"row going back":      `<row r="2"/><row r="1"/>`,
+		"row beyond limit":    `<row r="1048577"/>`,
+		"cell going back":     `<row r="1"><c r="C1"><v>1</v></c><c r="A1"><v>2</v></c></row>`,
+		"cell beyond limit":   `<row r="1"><c r="XFE1"><v>1</v></c></row>`,
+		"bad shared string":   `<row r="1"><c r="A1" t="s"><v>9</v></c></row>`,
+		"reference no letter": `<row r="1"><c r="11"><v>1</v></c></row>`,
+	}
+	for name, sheet := range tests {
+		if _, err := ReadXLSX(buildXLSX(t, sheet)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestXLSXRoundTrip(t *testing.T) {
+	in := [][]string{
+		{"id", "name", "notes"},
+		{"", "Acme", ""},
+		{},
+		{"2", "", "=1+1 & <tag>"},
+	}
+	var buf bytes.Buffer
+	w, err := NewXLSXWriter(&buf, "Organizations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range in {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := Read(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Las celdas vacías del final no se escriben
+	want := [][]string{
+		{"id", "name", "notes"},
+		{"", "Acme"},
+		{},
+		{"2", "", "=1+1 & <tag>"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
+
+func TestReadCSVDetectsSeparator(t *testing.T) {
+	rows, err := Read([]byte("\xef\xbb\xbfname;country\nAcme; AR\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"name", "country"}, {"Acme", "AR"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+	if _, err := Read([]byte(strings.Repeat("a,", 3) + "\n\"unterminated")); err == nil {
+		t.Error("expected an error for malformed CSV")
+	}
+}
@@ -0,0 +1,73 @@
+package tiles
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Cache guarda tiles generados hasta que se invalida (al publicar o archivar)
+// o vence su TTL. El TTL acota cuánto puede quedar desactualizada una
+// instancia que no recibió la invalidación de otra.
+type Cache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mutex   sync.RWMutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+func NewCache(ttl time.Duration, maxEntries int) *Cache {
+	return &Cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]cacheEntry),
+	}
+}
+
+// Get devuelve el tile cacheado para key, si existe y no venció.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		return nil, false
+	}
+	return e.data, true
+}
+
+// Set guarda un tile. Si el cache está lleno se vacía entero (los tiles se
+// regeneran rápido y esto evita mantener un LRU).
+func (c *Cache) Set(key string, data []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.entries) >= c.maxEntries {
+		c.entries = make(map[string]cacheEntry)
+	}
+	c.entries[key] = cacheEntry{data: data, expiresAt: time.Now().Add(c.ttl)}
+}
+
+// Invalidate descarta todos los tiles.
+func (c *Cache) Invalidate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries = make(map[string]cacheEntry)
+}
+
+// TTL devuelve la vigencia de cada entrada.
+func (c *Cache) TTL() time.Duration {
+	return c.ttl
+}
+
+// ETag deriva un ETag del contenido del tile, así coincide entre instancias.
+func ETag(data []byte) string {
+	h := fnv.New64a()
+	h.Write(data)
+	return fmt.Sprintf(`"%x"`, h.Sum64())
+}
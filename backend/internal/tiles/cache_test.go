@@ -0,0 +1,67 @@
+package tiles
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheGetSet(t *testing.T) {
+	c := NewCache(time.Minute, 10)
+	if _, ok := c.Get("0/0/0"); ok {
+		t.Fatal("empty cache returned an entry")
+	}
+	c.Set("0/0/0", []byte("tile"))
+	data, ok := c.Get("0/0/0")
+	if !ok || string(data) != "tile" {
+		t.Fatalf("Get = %q, %v", data, ok)
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	c := NewCache(time.Minute, 10)
+	c.Set("0/0/0", []byte("a"))
+	c.Set("1/0/0", []byte("b"))
+	c.Invalidate()
+	for _, key := range []string{"0/0/0", "1/0/0"} {
+		if _, ok := c.Get(key); ok {
+			t.Errorf("%s survived Invalidate", key)
+		}
+	}
+
+	// Después de invalidar el cache sigue funcionando
+	c.Set("0/0/0", []byte("c"))
+	if data, ok := c.Get("0/0/0"); !ok || string(data) != "c" {
+		t.Fatalf("Get after Invalidate = %q, %v", data, ok)
+	}
+}
+
+func TestCacheExpires(t *testing.T) {
+	c := NewCache(10*time.Millisecond, 10)
+	c.Set("0/0/0", []byte("a"))
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := c.Get("0/0/0"); ok {
+		t.Fatal("expired entry returned")
+	}
+}
+
+func TestCacheFlushesWhenFull(t *testing.T) {
+	c := NewCache(time.Minute, 2)
+	c.Set("a", []byte("a"))
+	c.Set("b", []byte("b"))
+	c.Set("c", []byte("c"))
+	if _, ok := c.Get("a"); ok {
+		t.Error("full cache was not flushed")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("entry that triggered the flush was lost")
+	}
+}
+
+func TestETagDependsOnContent(t *testing.T) {
+	if ETag([]byte("a")) == ETag([]byte("b")) {
+		t.Error("different tiles share an ETag")
+	}
+	if ETag([]byte("a")) != ETag([]byte("a")) {
+		t.Error("ETag is not stable")
+	}
+}
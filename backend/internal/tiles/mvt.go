@@ -0,0 +1,166 @@
+// Package tiles codifica Mapbox Vector Tiles (spec v2.1) con puntos y
+// mantiene un cache en memoria de tiles ya generados.
+package tiles
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Extent es la resolución de coordenadas dentro de un tile.
+const Extent = 4096
+
+// MaxZoom es el zoom máximo aceptado.
+const MaxZoom = 22
+
+// Bounds devuelve el área geográfica de un tile como minLat, minLng, maxLat, maxLng.
+func Bounds(z, x, y int) (float64, float64, float64, float64) {
+	n := math.Exp2(float64(z))
+	minLng := float64(x)/n*360 - 180
+	maxLng := float64(x+1)/n*360 - 180
+	maxLat := tileLat(float64(y), n)
+	minLat := tileLat(float64(y+1), n)
+	return minLat, minLng, maxLat, maxLng
+}
+
+func tileLat(y, n float64) float64 {
+	return math.Atan(math.Sinh(math.Pi*(1-2*y/n))) * 180 / math.Pi
+}
+
+// ValidateCoords verifica que z/x/y identifiquen un tile existente.
+func ValidateCoords(z, x, y int) error {
+	if z < 0 || z > MaxZoom {
+		return fmt.Errorf("zoom must be between 0 and %d", MaxZoom)
+	}
+	n := 1 << uint(z)
+	if x < 0 || x >= n || y < 0 || y >= n {
+		return fmt.Errorf("tile %d/%d/%d out of range", z, x, y)
+	}
+	return nil
+}
+
+// Point es un punto con propiedades de texto a codificar en una capa.
+type Point struct {
+	Lat        float64
+	Lng        float64
+	Properties map[string]string
+}
+
+// Encode arma un tile con una sola capa de puntos. Los puntos fuera del
+// tile se descartan.
+func Encode(layerName string, z, x, y int, points []Point) []byte {
+	n := math.Exp2(float64(z))
+
+	keys := make([]string, 0)
+	keyIndex := make(map[string]uint64)
+	values := make([]string, 0)
+	valueIndex := make(map[string]uint64)
+
+	var features []byte
+	for _, p := range points {
+		px, py := worldCoords(p.Lat, p.Lng, n)
+		tx := int64(math.Floor((px - float64(x)) * Extent))
+		ty := int64(math.Floor((py - float64(y)) * Extent))
+		if tx < 0 || tx >= Extent || ty < 0 || ty >= Extent {
+			continue
+		}
+
+		// Orden estable de propiedades: mismo contenido, mismos bytes (y ETag)
+		propKeys := make([]string, 0, len(p.Properties))
+		for k := range p.Properties {
+			propKeys = append(propKeys, k)
+		}
+		sort.Strings(propKeys)
+
+		var tags []uint64
+		for _, k := range propKeys {
+			v := p.Properties[k]
+			ki, ok := keyIndex[k]
+			if !ok {
+				ki = uint64(len(keys))
+				keyIndex[k] = ki
+				keys = append(keys, k)
+			}
+			vi, ok := valueIndex[v]
+			if !ok {
+				vi = uint64(len(values))
+				valueIndex[v] = vi
+				values = append(values, v)
+			}
+			tags = append(tags, ki, vi)
+		}
+
+		// Geometría: MoveTo(1) + dx, dy en zigzag
+		geometry := []uint64{commandInteger(1, 1), zigzag(tx), zigzag(ty)}
+
+		var feature []byte
+		feature = appendPacked(feature, 2, tags)
+		feature = appendVarintField(feature, 3, 1) // GeomType POINT
+		feature = appendPacked(feature, 4, geometry)
+		features = appendBytesField(features, 2, feature)
+	}
+
+	var layer []byte
+	layer = appendVarintField(layer, 15, 2) // version
+	layer = appendBytesField(layer, 1, []byte(layerName))
+	layer = append(layer, features...)
+	for _, k := range keys {
+		layer = appendBytesField(layer, 3, []byte(k))
+	}
+	for _, v := range values {
+		var value []byte
+		value = appendBytesField(value, 1, []byte(v)) // string_value
+		layer = appendBytesField(layer, 4, value)
+	}
+	layer = appendVarintField(layer, 5, Extent)
+
+	return appendBytesField(nil, 3, layer)
+}
+
+// worldCoords proyecta a Web Mercator en unidades de tile (0..n).
+func worldCoords(lat, lng, n float64) (float64, float64) {
+	lat = math.Max(math.Min(lat, 85.05112878), -85.05112878)
+	sin := math.Sin(lat * math.Pi / 180)
+	x := (lng + 180) / 360 * n
+	y := (0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)) * n
+	// En el límite de la proyección el redondeo puede dar apenas menos de 0
+	return x, math.Max(0, math.Min(y, n))
+}
+
+// --- protobuf mínimo ---
+
+func commandInteger(id, count uint64) uint64 {
+	return (id & 0x7) | (count << 3)
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendVarintField(b []byte, field int, v uint64) []byte {
+	b = appendVarint(b, uint64(field)<<3|0)
+	return appendVarint(b, v)
+}
+
+func appendBytesField(b []byte, field int, data []byte) []byte {
+	b = appendVarint(b, uint64(field)<<3|2)
+	b = appendVarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+func appendPacked(b []byte, field int, values []uint64) []byte {
+	var packed []byte
+	for _, v := range values {
+		packed = appendVarint(packed, v)
+	}
+	return appendBytesField(b, field, packed)
+}
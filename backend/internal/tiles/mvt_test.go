@@ -0,0 +1,285 @@
+package tiles
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// pbField es un campo protobuf decodificado: varint o bytes (wire types 0 y 2).
+type pbField struct {
+	num    int
+	varint uint64
+	bytes  []byte
+}
+
+func readVarint(t *testing.T, b []byte) (uint64, []byte) {
+	t.Helper()
+	var v uint64
+	for shift := uint(0); ; shift += 7 {
+		if len(b) == 0 {
+			t.Fatal("truncated varint")
+		}
+		c := b[0]
+		b = b[1:]
+		v |= uint64(c&0x7f) << shift
+		if c < 0x80 {
+			return v, b
+		}
+	}
+}
+
+func decodeMessage(t *testing.T, b []byte) []pbField {
+	t.Helper()
+	var fields []pbField
+	for len(b) > 0 {
+		var key uint64
+		key, b = readVarint(t, b)
+		f := pbField{num: int(key >> 3)}
+		switch key & 0x7 {
+		case 0:
+			f.varint, b = readVarint(t, b)
+		case 2:
+			var n uint64
+			n, b = readVarint(t, b)
+			if uint64(len(b)) < n {
+				t.Fatalf("field %d: truncated bytes", f.num)
+			}
+			f.bytes, b = b[:n], b[n:]
+		default:
+			t.Fatalf("field %d: unexpected wire type %d", f.num, key&0x7)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func decodePacked(t *testing.T, b []byte) []uint64 {
+	t.Helper()
+	var values []uint64
+	for len(b) > 0 {
+		var v uint64
+		v, b = readVarint(t, b)
+		values = append(values, v)
+	}
+	return values
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// decodedPoint es un feature ya resuelto contra las tablas de la capa.
+type decodedPoint struct {
+	X, Y       int64
+	Properties map[string]string
+}
+
+type decodedLayer struct {
+	Name    string
+	Version uint64
+	Extent  uint64
+	Points  []decodedPoint
+}
+
+// decodeTile lee un tile de una capa de puntos tal como lo arma Encode.
+func decodeTile(t *testing.T, data []byte) decodedLayer {
+	t.Helper()
+	tile := decodeMessage(t, data)
+	if len(tile) != 1 || tile[0].num != 3 {
+		t.Fatalf("tile must have exactly one layer, got %+v", tile)
+	}
+
+	var layer decodedLayer
+	var keys, values []string
+	var features [][]pbField
+	for _, f := range decodeMessage(t, tile[0].bytes) {
+		switch f.num {
+		case 15:
+			layer.Version = f.varint
+		case 1:
+			layer.Name = string(f.bytes)
+		case 2:
+			features = append(features, decodeMessage(t, f.bytes))
+		case 3:
+			keys = append(keys, string(f.bytes))
+		case 4:
+			value := decodeMessage(t, f.bytes)
+			if len(value) != 1 || value[0].num != 1 {
+				t.Fatalf("value must be a single string_value, got %+v", value)
+			}
+			values = append(values, string(value[0].bytes))
+		case 5:
+			layer.Extent = f.varint
+		default:
+			t.Fatalf("unexpected layer field %d", f.num)
+		}
+	}
+
+	for _, feature := range features {
+		p := decodedPoint{Properties: map[string]string{}}
+		for _, f := range feature {
+			switch f.num {
+			case 2:
+				tags := decodePacked(t, f.bytes)
+				if len(tags)%2 != 0 {
+					t.Fatalf("odd number of tags: %v", tags)
+				}
+				for i := 0; i < len(tags); i += 2 {
+					if tags[i] >= uint64(len(keys)) || tags[i+1] >= uint64(len(values)) {
+						t.Fatalf("tag out of range: %v", tags)
+					}
+					p.Properties[keys[tags[i]]] = values[tags[i+1]]
+				}
+			case 3:
+				if f.varint != 1 {
+					t.Fatalf("geometry type = %d, want POINT (1)", f.varint)
+				}
+			case 4:
+				geometry := decodePacked(t, f.bytes)
+				if len(geometry) != 3 || geometry[0] != commandInteger(1, 1) {
+					t.Fatalf("geometry must be a single MoveTo, got %v", geometry)
+				}
+				p.X, p.Y = unzigzag(geometry[1]), unzigzag(geometry[2])
+			}
+		}
+		layer.Points = append(layer.Points, p)
+	}
+	return layer
+}
+
+func TestZigzag(t *testing.T) {
+	tests := []struct {
+		in   int64
+		want uint64
+	}{
+		{0, 0},
+		{-1, 1},
+		{1, 2},
+		{-2, 3},
+		{2048, 4096},
+		{-4096, 8191},
+	}
+	for _, tt := range tests {
+		if got := zigzag(tt.in); got != tt.want {
+			t.Errorf("zigzag(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+		if back := unzigzag(tt.want); back != tt.in {
+			t.Errorf("unzigzag(%d) = %d, want %d", tt.want, back, tt.in)
+		}
+	}
+}
+
+func TestCommandInteger(t *testing.T) {
+	if got := commandInteger(1, 1); got != 9 {
+		t.Errorf("MoveTo(1) = %d, want 9", got)
+	}
+	if got := commandInteger(2, 3); got != 26 {
+		t.Errorf("LineTo(3) = %d, want 26", got)
+	}
+	if got := commandInteger(7, 1); got != 15 {
+		t.Errorf("ClosePath(1) = %d, want 15", got)
+	}
+}
+
+func TestEncodeGeometry(t *testing.T) {
+	tests := []struct {
+		name     string
+		z, x, y  int
+		lat, lng float64
+		want     []int64 // nil = fuera del tile
+	}{
+		{"center of world tile", 0, 0, 0, 0, 0, []int64{2048, 2048}},
+		{"west edge", 0, 0, 0, 0, -180, []int64{0, 2048}},
+		{"quarter east", 0, 0, 0, 0, 90, []int64{3072, 2048}},
+		{"origin of south-east tile", 1, 1, 1, 0, 0, []int64{0, 0}},
+		{"north-west tile excludes origin", 1, 0, 0, 0, 0, nil},
+		{"clamped above mercator limit", 0, 0, 0, 89.9, 0, []int64{2048, 0}},
+		{"point in other tile is dropped", 2, 0, 0, -10, 100, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layer := decodeTile(t, Encode("orgs", tt.z, tt.x, tt.y, []Point{{Lat: tt.lat, Lng: tt.lng}}))
+			if tt.want == nil {
+				if len(layer.Points) != 0 {
+					t.Fatalf("expected no features, got %+v", layer.Points)
+				}
+				return
+			}
+			if len(layer.Points) != 1 {
+				t.Fatalf("expected 1 feature, got %d", len(layer.Points))
+			}
+			if got := []int64{layer.Points[0].X, layer.Points[0].Y}; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tile coords = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeLayerAndProperties(t *testing.T) {
+	points := []Point{
+		{Lat: 10, Lng: 10, Properties: map[string]string{"id": "a", "name": "Alpha", "country": "AR"}},
+		{Lat: -10, Lng: -10, Properties: map[string]string{"id": "b", "name": "Beta", "country": "AR"}},
+		{Lat: 0, Lng: 0, Properties: map[string]string{}},
+	}
+	data := Encode("organizations", 0, 0, 0, points)
+	layer := decodeTile(t, data)
+
+	if layer.Name != "organizations" || layer.Version != 2 || layer.Extent != Extent {
+		t.Fatalf("layer header = %q v%d extent %d", layer.Name, layer.Version, layer.Extent)
+	}
+	if len(layer.Points) != len(points) {
+		t.Fatalf("features = %d, want %d", len(layer.Points), len(points))
+	}
+	for i, p := range points {
+		if !reflect.DeepEqual(layer.Points[i].Properties, p.Properties) {
+			t.Errorf("feature %d properties = %v, want %v", i, layer.Points[i].Properties, p.Properties)
+		}
+	}
+
+	// Mismo contenido (aunque el map itere distinto) = mismos bytes y mismo ETag
+	again := Encode("organizations", 0, 0, 0, points)
+	if ETag(again) != ETag(data) {
+		t.Error("encoding is not deterministic")
+	}
+}
+
+func TestEncodeEmpty(t *testing.T) {
+	layer := decodeTile(t, Encode("orgs", 3, 1, 2, nil))
+	if layer.Name != "orgs" || len(layer.Points) != 0 {
+		t.Fatalf("unexpected layer %+v", layer)
+	}
+}
+
+func TestBoundsRoundTrip(t *testing.T) {
+	for _, c := range [][3]int{{0, 0, 0}, {3, 2, 5}, {10, 301, 612}} {
+		z, x, y := c[0], c[1], c[2]
+		t.Run(fmt.Sprintf("%d/%d/%d", z, x, y), func(t *testing.T) {
+			minLat, minLng, maxLat, maxLng := Bounds(z, x, y)
+			// El centro del recuadro cae dentro del tile
+			layer := decodeTile(t, Encode("orgs", z, x, y, []Point{{Lat: (minLat + maxLat) / 2, Lng: (minLng + maxLng) / 2}}))
+			if len(layer.Points) != 1 {
+				t.Fatalf("center of bounds not encoded in its own tile")
+			}
+		})
+	}
+}
+
+func TestValidateCoords(t *testing.T) {
+	tests := []struct {
+		z, x, y int
+		ok      bool
+	}{
+		{0, 0, 0, true},
+		{2, 3, 3, true},
+		{2, 4, 0, false},
+		{-1, 0, 0, false},
+		{MaxZoom + 1, 0, 0, false},
+		{1, 0, -1, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateCoords(tt.z, tt.x, tt.y); (err == nil) != tt.ok {
+			t.Errorf("ValidateCoords(%d, %d, %d) = %v, want ok=%v", tt.z, tt.x, tt.y, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+-- Migración: historial de cambios por campo en audit_logs
+-- 001_init.sql creó field_name/old_value/new_value; el código además usa
+-- action/from_status/to_status/performed_by, que se agregan aquí si faltan.
+
+ALTER TABLE audit_logs
+    ADD COLUMN IF NOT EXISTS action VARCHAR(50) NULL,
+    ADD COLUMN IF NOT EXISTS from_status VARCHAR(20) NULL,
+    ADD COLUMN IF NOT EXISTS to_status VARCHAR(20) NULL,
+    ADD COLUMN IF NOT EXISTS performed_by VARCHAR(100) NULL,
+    ADD COLUMN IF NOT EXISTS performed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- Consultas frecuentes: historial de una entidad ordenado por fecha
+CREATE INDEX IF NOT EXISTS idx_audit_entity ON audit_logs (entity_type, entity_id, performed_at);
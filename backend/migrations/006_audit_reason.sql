@@ -0,0 +1,5 @@
+-- Migración: motivo opcional en eventos de auditoría
+-- Lo usan las transiciones que lo requieren (p.ej. REJECT) o lo aceptan (RESTORE).
+
+ALTER TABLE audit_logs
+    ADD COLUMN IF NOT EXISTS reason TEXT NULL;
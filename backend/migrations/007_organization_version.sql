@@ -0,0 +1,5 @@
+-- Migración: control de concurrencia optimista
+-- version se incrementa en cada escritura; la API la expone como ETag.
+
+ALTER TABLE organizations
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,6 @@
+-- Migración: búsqueda full-text con ranking por relevancia
+-- El orden de columnas debe coincidir con searchMatchColumns en el código.
+
+ALTER TABLE organizations
+    ADD FULLTEXT INDEX IF NOT EXISTS ft_organizations_search
+        (name, description, city, region, country, tags_json, technology_json);
@@ -0,0 +1,10 @@
+-- Migración: alias de organizaciones fusionadas
+-- Cada id absorbido por un merge apunta a la organización que sobrevive,
+-- para que los links públicos viejos redirijan (301).
+
+CREATE TABLE IF NOT EXISTS organization_aliases (
+    alias_id CHAR(36) PRIMARY KEY,
+    organization_id CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_alias_organization (organization_id)
+);
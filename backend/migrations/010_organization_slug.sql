@@ -0,0 +1,10 @@
+-- Migración: slug legible y único por organización
+-- Las filas existentes reciben su slug al iniciar la API (Service.BackfillSlugs).
+-- alias_id pasa a admitir slugs de organizaciones fusionadas.
+
+ALTER TABLE organizations
+    ADD COLUMN IF NOT EXISTS slug VARCHAR(255) NULL AFTER id,
+    ADD UNIQUE INDEX IF NOT EXISTS ux_organizations_slug (slug);
+
+ALTER TABLE organization_aliases
+    MODIFY alias_id VARCHAR(255) NOT NULL;
@@ -0,0 +1,13 @@
+-- Migración: snapshots completos de cada versión de una organización
+-- version coincide con organizations.version (y con el ETag) al momento del snapshot.
+-- Cada escritura guarda, en su misma transacción, el snapshot resultante y, si
+-- la versión previa todavía no tiene (filas anteriores a esta tabla), también ese.
+
+CREATE TABLE IF NOT EXISTS organization_versions (
+    organization_id CHAR(36) NOT NULL,
+    version INT NOT NULL,
+    snapshot LONGTEXT NOT NULL,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, version)
+);
@@ -0,0 +1,19 @@
+-- Migración: revisiones pendientes de organizaciones publicadas
+-- Editar una organización PUBLISHED no toca la fila publicada: el cambio queda
+-- aquí (snapshot propuesto + snapshot base) hasta que se aprueba o descarta.
+-- Hay a lo sumo una revisión abierta (DRAFT o IN_REVIEW) por organización (índice en 014).
+
+CREATE TABLE IF NOT EXISTS organization_revisions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    organization_id CHAR(36) NOT NULL,
+    base_version INT NOT NULL,
+    base_snapshot LONGTEXT NOT NULL,
+    snapshot LONGTEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'DRAFT',
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    decided_by VARCHAR(100),
+    decided_at TIMESTAMP NULL,
+    INDEX idx_organization_revisions_org (organization_id, status)
+);
@@ -0,0 +1,13 @@
+-- Migración: publicación programada
+-- publish_at / unpublish_at se guardan en UTC y se comparan con UTC_TIMESTAMP().
+-- schedule_error guarda el último fallo del scheduler; se reintenta cuando la
+-- organización se edita después de schedule_failed_at.
+
+ALTER TABLE organizations
+    ADD COLUMN IF NOT EXISTS publish_at DATETIME NULL,
+    ADD COLUMN IF NOT EXISTS unpublish_at DATETIME NULL,
+    ADD COLUMN IF NOT EXISTS schedule_error VARCHAR(1000) NULL,
+    ADD COLUMN IF NOT EXISTS schedule_failed_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_organizations_publish_at ON organizations (status, publish_at);
+CREATE INDEX IF NOT EXISTS idx_organizations_unpublish_at ON organizations (status, unpublish_at);
@@ -0,0 +1,9 @@
+-- Migración: a lo sumo una revisión abierta por organización
+-- open_key vale organization_id mientras la revisión está DRAFT o IN_REVIEW y
+-- NULL al cerrarse; el índice único (que admite varios NULL) hace cumplir la regla.
+
+ALTER TABLE organization_revisions
+    ADD COLUMN IF NOT EXISTS open_key CHAR(36)
+        AS (IF(status IN ('DRAFT', 'IN_REVIEW'), organization_id, NULL)) PERSISTENT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_organization_revisions_open ON organization_revisions (open_key);
@@ -27,10 +27,29 @@ function cleanParams(params) {
     return new URLSearchParams(cleaned).toString();
 }
 
+/**
+ * Lists are paginated server-side (X-Next-Cursor); follow cursors until the last page
+ */
+async function fetchAllPages(path, params, options = {}) {
+    const query = cleanParams({ limit: 500, ...params });
+    const items = [];
+    let cursor = '';
+    do {
+        const url = `${API_URL}${path}?${query}${cursor ? `&cursor=${encodeURIComponent(cursor)}` : ''}`;
+        const response = await fetch(url, options);
+        if (!response.ok) {
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+        items.push(...(await response.json()));
+        cursor = response.headers.get('X-Next-Cursor') || '';
+    } while (cursor);
+    return items;
+}
+
 // Public Endpoints
+// Every match is needed for the map, not just the first page
 export const fetchOrganizations = async (params = {}, signal) => {
-    const query = cleanParams(params);
-    return fetchWithSignal(`${API_URL}/public/organizations?${query}`, { signal });
+    return fetchAllPages('/public/organizations', params, { signal });
 };
 
 export const fetchOrganizationById = async (id, signal) => {
@@ -47,8 +66,9 @@ export const fetchTaxonomies = async (signal) => {
 };
 
 // Admin Endpoints
-export const adminFetchOrganizations = async () => {
-    return fetchWithSignal(`${API_URL}/organizations`, {
+export const adminFetchOrganizations = async (params = {}, signal) => {
+    return fetchAllPages('/organizations', params, {
+        signal,
         headers: { Authorization: `Bearer ${ADMIN_TOKEN}` }
     });
 };